@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/aws/logs"
+	"github.com/jedipunkz/miniecs/internal/pkg/picker"
+	log "github.com/sirupsen/logrus"
+)
+
+const actionShell = "shell"
+
+// logsActionLimit is the number of log lines shown by the logs action.
+const logsActionLimit = 100
+
+// targetAction is something to do with a container chosen in the login
+// picker, instead of logging in to it.
+type targetAction struct {
+	name        string
+	key         string
+	description string
+	// mutating actions are refused in readonly contexts.
+	mutating bool
+	run      func(ctx context.Context, ecsClient *myecs.ECSResource, resource myecs.ECSResource) error
+}
+
+// targetActions are offered in the order listed. The first one is the
+// login itself, chosen with Enter.
+var targetActions = []targetAction{
+	{name: actionShell, key: "enter", description: "log in to the container"},
+	{name: "logs", key: "ctrl-l", description: "show the last log lines of the container", run: runLogsAction},
+	{name: "describe", key: "ctrl-d", description: "describe the task and the container", run: runDescribeAction},
+	{name: "stop", key: "ctrl-x", description: "stop the task", mutating: true, run: runStopAction},
+	{name: "copy-arn", key: "ctrl-y", description: "copy the task ARN to the clipboard", run: runCopyARNAction},
+	{name: "port-forward", key: "ctrl-p", description: "forward local ports to the container", mutating: true, run: runPortForwardAction},
+}
+
+// actionKeys returns the keys accepting the selection besides Enter.
+func actionKeys() []string {
+	var keys []string
+	for _, action := range targetActions[1:] {
+		keys = append(keys, action.key)
+	}
+	return keys
+}
+
+func actionKeysHeader() string {
+	var hints []string
+	for _, action := range targetActions {
+		hints = append(hints, action.key+": "+action.name)
+	}
+	return strings.Join(hints, "  ")
+}
+
+// actionForKey returns the name of the action bound to key, empty for
+// Enter and unknown keys.
+func actionForKey(key string) string {
+	for _, action := range targetActions[1:] {
+		if action.key == key {
+			return action.name
+		}
+	}
+	return ""
+}
+
+func findAction(name string) (targetAction, bool) {
+	for _, action := range targetActions {
+		if action.name == name {
+			return action, true
+		}
+	}
+	return targetAction{}, false
+}
+
+// pickAction asks what to do with resources.
+func pickAction(p picker.Picker, resources []myecs.ECSResource) (string, error) {
+	labels := make([]string, len(targetActions))
+	for i, action := range targetActions {
+		labels[i] = fmt.Sprintf("%-12s %s", action.name, action.description)
+	}
+	header := "Action"
+	if len(resources) == 1 {
+		header += " for " + resourcePath(resources[0])
+	}
+	selected, err := p.Pick(labels, picker.Options{Header: header})
+	if err != nil {
+		return "", err
+	}
+	return targetActions[selected[0]].name, nil
+}
+
+// runTargetAction runs the named action on the single container of
+// resources.
+func runTargetAction(ctx context.Context, ecsClient *myecs.ECSResource, name string, resources []myecs.ECSResource) error {
+	action, ok := findAction(name)
+	if !ok {
+		return fmt.Errorf("unknown action %q", name)
+	}
+	if len(resources) != 1 {
+		return fmt.Errorf("%s works on a single container, %d were selected", action.name, len(resources))
+	}
+	if action.mutating {
+		if err := refuseInReadonly(action.name); err != nil {
+			return err
+		}
+	}
+	return action.run(ctx, ecsClient, resources[0])
+}
+
+// resourceItem is the selectableItem of a resource holding a single
+// container, as made by toResource.
+func resourceItem(resource myecs.ECSResource) selectableItem {
+	cluster := resource.Clusters[0]
+	service := cluster.Services[0]
+	task := service.Tasks[0]
+	return selectableItem{
+		cluster:   cluster,
+		service:   service,
+		task:      task,
+		container: task.Containers[0],
+	}
+}
+
+func resourcePath(resource myecs.ECSResource) string {
+	item := resourceItem(resource)
+	return item.cluster.ClusterName + "/" + item.service.ServiceName + "/" + item.container.ContainerName
+}
+
+// tailLogs returns the last log events of a log stream.
+var tailLogs = func(ctx context.Context, region, group, stream string, limit int32) ([]logs.Event, error) {
+	return logs.NewLogs(sessionConfig, region).Tail(ctx, group, stream, limit)
+}
+
+func runLogsAction(ctx context.Context, ecsClient *myecs.ECSResource, resource myecs.ECSResource) error {
+	item := resourceItem(resource)
+	stream := item.container.LogStream(item.task.TaskArn)
+	if stream == "" {
+		return fmt.Errorf("container %s does not send its logs to CloudWatch Logs with the awslogs driver and a stream prefix", item.container.ContainerName)
+	}
+	region := item.container.LogRegion
+	if region == "" {
+		region = ecsClient.Region
+	}
+
+	events, err := tailLogs(ctx, region, item.container.LogGroup, stream, logsActionLimit)
+	if err != nil {
+		return err
+	}
+	return writeLogEvents(os.Stdout, events)
+}
+
+func writeLogEvents(w io.Writer, events []logs.Event) error {
+	for _, event := range events {
+		if _, err := fmt.Fprintf(w, "%s %s\n", event.Time.Local().Format(time.RFC3339), strings.TrimRight(event.Message, "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runDescribeAction(ctx context.Context, ecsClient *myecs.ECSResource, resource myecs.ECSResource) error {
+	_, err := io.WriteString(os.Stdout, describeItem(resourceItem(resource), time.Now()))
+	return err
+}
+
+// describeItem is the picker preview of item followed by its ARNs.
+func describeItem(item selectableItem, now time.Time) string {
+	description := itemPreview(item, now)
+	for _, arn := range []struct{ label, value string }{
+		{"Task ARN", item.task.TaskArn},
+		{"Task def", item.task.TaskDefinition},
+		{"Container ARN", item.container.ContainerArn},
+	} {
+		if arn.value != "" {
+			description += fmt.Sprintf("%-14s %s\n", arn.label+":", arn.value)
+		}
+	}
+	return description
+}
+
+func runStopAction(ctx context.Context, ecsClient *myecs.ECSResource, resource myecs.ECSResource) error {
+	item := resourceItem(resource)
+	confirm := func() error {
+		return confirmYes(fmt.Sprintf("Stop task %s of %s/%s?",
+			taskID(item.task.TaskArn), item.cluster.ClusterName, item.service.ServiceName))
+	}
+	if isProduction(item.cluster.ClusterName) && appConfig.Production.Confirm {
+		confirm = func() error { return confirmProduction([]myecs.ECSResource{resource}) }
+	}
+	if err := confirm(); err != nil {
+		return err
+	}
+
+	if err := ecsClient.StopTask(ctx, item.cluster.ClusterName, item.task.TaskArn, "Stopped with miniecs"); err != nil {
+		return err
+	}
+	log.Infof("Stopped task %s", taskID(item.task.TaskArn))
+	return nil
+}
+
+func runCopyARNAction(ctx context.Context, ecsClient *myecs.ECSResource, resource myecs.ECSResource) error {
+	arn := resourceItem(resource).task.TaskArn
+	if err := copyToClipboard(arn); err != nil {
+		log.Warnf("failed to copy to the clipboard: %v", err)
+		fmt.Println(arn)
+		return nil
+	}
+	log.Infof("Copied %s", arn)
+	return nil
+}
+
+func runPortForwardAction(ctx context.Context, ecsClient *myecs.ECSResource, resource myecs.ECSResource) error {
+	in, err := confirmInput()
+	if err != nil {
+		return fmt.Errorf("port-forward asks for the ports but no terminal is available, use miniecs port-forward: %w", err)
+	}
+	fmt.Fprint(os.Stderr, "Ports to forward, [local:]remote separated by spaces: ")
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	in.Close()
+
+	forwards, err := parsePortForwards(strings.Fields(answer))
+	if err != nil {
+		return err
+	}
+	if err := confirmProduction([]myecs.ECSResource{resource}); err != nil {
+		return err
+	}
+	return withService(forwardPorts(ctx, ecsClient, resource, forwards), resource)
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/aws/logs"
+	"github.com/jedipunkz/miniecs/internal/pkg/config"
+	"github.com/jedipunkz/miniecs/internal/pkg/picker"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeKeyPicker accepts the first entry with key, or with the keys in
+// order when several are given.
+type fakeKeyPicker struct {
+	key    string
+	keys   []string
+	header string
+	labels [][]string
+}
+
+func (p *fakeKeyPicker) Pick(labels []string, opts picker.Options) ([]int, error) {
+	selected, _, err := p.PickKey(labels, opts, nil)
+	return selected, err
+}
+
+func (p *fakeKeyPicker) PickKey(labels []string, opts picker.Options, keys []string) ([]int, string, error) {
+	p.header = opts.Header
+	p.labels = append(p.labels, labels)
+	if len(p.keys) > 0 {
+		key := p.keys[0]
+		p.keys = p.keys[1:]
+		return []int{0}, key, nil
+	}
+	return []int{0}, p.key, nil
+}
+
+func TestActionKeys(t *testing.T) {
+	assert.Equal(t, []string{"ctrl-l", "ctrl-d", "ctrl-x", "ctrl-y", "ctrl-p"}, actionKeys())
+	assert.Equal(t, "logs", actionForKey("ctrl-l"))
+	assert.Equal(t, "", actionForKey(""))
+	assert.Contains(t, actionKeysHeader(), "enter: shell  ctrl-l: logs")
+}
+
+func TestPickSelectableItemsAction(t *testing.T) {
+	items := testSelectableItems()[:4]
+
+	p := &fakeKeyPicker{key: "ctrl-d"}
+	resources, action, err := pickSelectableItems(p, items, false, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "describe", action)
+	assert.Equal(t, "prod/api/app", resourcePath(resources[0]))
+	assert.Equal(t, actionKeysHeader()+"  ctrl-s: show 1 sidecar containers", p.header)
+
+	// keys are only offered when asked for
+	p = &fakeKeyPicker{key: "ctrl-d"}
+	_, action, err = pickSelectableItems(p, items, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "", action)
+}
+
+func TestPickAction(t *testing.T) {
+	p := &fakePicker{t: t, choices: []int{2}}
+	action, err := pickAction(p, itemsToResources(testSelectableItems()[:1]))
+	assert.NoError(t, err)
+	assert.Equal(t, "describe", action)
+	assert.Equal(t, []string{"Action for prod/api/app"}, p.headers)
+}
+
+func TestRunTargetAction(t *testing.T) {
+	withGuardConfig(t, map[string]config.ContextConfig{"prod": {Readonly: true}}, "prod")
+	resources := itemsToResources(testSelectableItems())
+
+	assert.ErrorContains(t, runTargetAction(context.Background(), nil, "logs", resources), "single container")
+	assert.ErrorContains(t, runTargetAction(context.Background(), nil, "stop", resources[:1]), "readonly context")
+	assert.ErrorContains(t, runTargetAction(context.Background(), nil, "port-forward", resources[:1]), "readonly context")
+	assert.ErrorContains(t, runTargetAction(context.Background(), nil, "reboot", resources[:1]), "unknown action")
+}
+
+func TestRunStopActionNotConfirmed(t *testing.T) {
+	withGuardConfig(t, nil, "")
+	savedInput := confirmInput
+	t.Cleanup(func() { confirmInput = savedInput })
+	confirmInput = func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("n\n")), nil
+	}
+
+	// stg is no production cluster, so a yes is enough
+	resource := itemsToResources(testSelectableItems()[3:4])[0]
+	assert.ErrorContains(t, runStopAction(context.Background(), nil, resource), "not confirmed")
+}
+
+func TestRunPortForwardAction(t *testing.T) {
+	withGuardConfig(t, nil, "")
+	savedInput := confirmInput
+	t.Cleanup(func() { confirmInput = savedInput })
+	answer := ""
+	confirmInput = func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(answer)), nil
+	}
+	resource := itemsToResources(testSelectableItems()[3:4])[0]
+
+	answer = "http\n"
+	assert.ErrorContains(t, runPortForwardAction(context.Background(), &myecs.ECSResource{}, resource), `invalid port "http"`)
+
+	answer = "\n"
+	assert.ErrorContains(t, runPortForwardAction(context.Background(), &myecs.ECSResource{}, resource), "no port given")
+
+	// the ports are forwarded with the session of the port-forward command
+	answer = "8080:80\n"
+	assert.ErrorContains(t, runPortForwardAction(context.Background(), &myecs.ECSResource{}, resource), "SSM client is not initialized")
+}
+
+func TestRunLogsAction(t *testing.T) {
+	saved := tailLogs
+	t.Cleanup(func() { tailLogs = saved })
+	var called []string
+	tailLogs = func(ctx context.Context, region, group, stream string, limit int32) ([]logs.Event, error) {
+		called = []string{region, group, stream}
+		return nil, nil
+	}
+
+	item := testSelectableItems()[0]
+	assert.ErrorContains(t, runLogsAction(context.Background(), &myecs.ECSResource{Region: "ap-northeast-1"}, item.toResource()), "awslogs")
+
+	item.container.LogGroup = "/ecs/api"
+	item.container.LogStreamPrefix = "ecs"
+	assert.NoError(t, runLogsAction(context.Background(), &myecs.ECSResource{Region: "ap-northeast-1"}, item.toResource()))
+	assert.Equal(t, []string{"ap-northeast-1", "/ecs/api", "ecs/app/task1"}, called)
+}
+
+func TestWriteLogEvents(t *testing.T) {
+	var buf bytes.Buffer
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, writeLogEvents(&buf, []logs.Event{{Time: started, Message: "booting\n"}}))
+	assert.Equal(t, started.Local().Format(time.RFC3339)+" booting\n", buf.String())
+}
+
+func TestDescribeItem(t *testing.T) {
+	item := testSelectableItems()[3]
+	description := describeItem(item, time.Now())
+	assert.Contains(t, description, "Cluster:    stg\n")
+	assert.Contains(t, description, "Task ARN:      "+item.task.TaskArn+"\n")
+}
+
+func TestCopyToClipboard(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake clipboard is a shell script")
+	}
+	saved := clipboardCommands
+	t.Cleanup(func() { clipboardCommands = saved })
+
+	dir := t.TempDir()
+	copied := filepath.Join(dir, "copied")
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "fakeclip"), []byte("#!/bin/sh\ncat > "+copied+"\n"), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	clipboardCommands = [][]string{{"miniecs-missing"}, {"fakeclip"}}
+	assert.NoError(t, copyToClipboard("arn:aws:ecs:task"))
+	data, err := os.ReadFile(copied)
+	assert.NoError(t, err)
+	assert.Equal(t, "arn:aws:ecs:task", string(data))
+
+	clipboardCommands = [][]string{{"miniecs-missing"}}
+	assert.EqualError(t, copyToClipboard("arn"), "none of miniecs-missing is installed")
+}
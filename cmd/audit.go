@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/jedipunkz/miniecs/internal/pkg/audit"
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/redact"
+	log "github.com/sirupsen/logrus"
+)
+
+// callerIdentity returns the ARN and account of the AWS principal in cfg.
+var callerIdentity = func(ctx context.Context, cfg aws.Config) (string, string, error) {
+	out, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", "", err
+	}
+	return aws.ToString(out.Arn), aws.ToString(out.Account), nil
+}
+
+// awsIdentity looks up the caller identity of an AWS config on first use.
+type awsIdentity struct {
+	once    sync.Once
+	cfg     aws.Config
+	caller  string
+	account string
+}
+
+func newAWSIdentity(cfg aws.Config) *awsIdentity {
+	return &awsIdentity{cfg: cfg}
+}
+
+// get returns the caller ARN and account, empty when they cannot be looked
+// up.
+func (id *awsIdentity) get() (string, string) {
+	if id == nil {
+		return "", ""
+	}
+	id.once.Do(func() {
+		var err error
+		id.caller, id.account, err = callerIdentity(context.Background(), id.cfg)
+		if err != nil {
+			log.Debugf("failed to get caller identity: %v", err)
+		}
+	})
+	return id.caller, id.account
+}
+
+// sessionIdentity is the identity of the client made by
+// initializeECSClient.
+var sessionIdentity *awsIdentity
+
+// enableAudit appends every session of ecsClient to the audit log. Failing
+// to write the log is reported but never stops a session.
+func enableAudit(ecsClient *myecs.ECSResource, identity *awsIdentity) {
+	if !appConfig.Audit.Enabled {
+		return
+	}
+	path, err := appConfig.AuditPath()
+	if err != nil {
+		log.Warnf("audit log disabled: %v", err)
+		return
+	}
+	auditLog := audit.NewLog(path)
+
+	addSessionHook(ecsClient, func(session myecs.Session) {
+		entry := auditEntry(session, ecsClient.Region)
+		entry.Caller, entry.Account = identity.get()
+		if err := auditLog.Append(entry); err != nil {
+			log.Warn(err)
+		}
+	})
+}
+
+// addSessionHook calls hook for every session of ecsClient, after the hooks
+// added before it.
+func addSessionHook(ecsClient *myecs.ECSResource, hook func(myecs.Session)) {
+	previous := ecsClient.OnSession
+	ecsClient.OnSession = func(session myecs.Session) {
+		if previous != nil {
+			previous(session)
+		}
+		hook(session)
+	}
+}
+
+// auditCommands maps the command lines that set --env variables to the
+// same command lines with the values masked, for the audit log.
+var auditCommands sync.Map
+
+// commandLine returns the command line of spec. The values of --env
+// variables are masked in the command line written to the audit log,
+// whatever their names.
+func commandLine(spec myecs.LoginSpec) string {
+	line := spec.CommandLine()
+	if len(spec.Env) > 0 {
+		masked := spec
+		masked.Env = make([]string, len(spec.Env))
+		for i, env := range spec.Env {
+			key, _, _ := strings.Cut(env, "=")
+			masked.Env[i] = key + "=" + redact.Mask
+		}
+		auditCommands.Store(line, masked.CommandLine())
+	}
+	return line
+}
+
+func auditEntry(session myecs.Session, region string) audit.Entry {
+	command := session.Command
+	if masked, ok := auditCommands.Load(command); ok {
+		command = masked.(string)
+	}
+	entry := audit.Entry{
+		Time:      session.Started.UTC(),
+		Region:    region,
+		Cluster:   session.Cluster,
+		Task:      session.Task,
+		Container: session.Container,
+		Command:   secretRedactor.String(command),
+		SessionID: session.SessionID,
+		Duration:  session.Duration.Seconds(),
+		Detached:  session.Detached,
+	}
+	if session.ExitCode >= 0 {
+		code := session.ExitCode
+		entry.ExitCode = &code
+	}
+	if session.Err != nil && !isRemoteExit(session.Err) {
+		entry.Error = secretRedactor.String(session.Err.Error())
+	}
+	return entry
+}
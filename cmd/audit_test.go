@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/jedipunkz/miniecs/internal/pkg/audit"
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditEntry(t *testing.T) {
+	started := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	session := myecs.Session{
+		Cluster:   "prod",
+		Task:      "arn:aws:ecs:ap-northeast-1:123456789012:task/prod/abc",
+		Container: "app",
+		Command:   "cat REVISION",
+		SessionID: "ecs-execute-command-1",
+		Started:   started,
+		Duration:  1500 * time.Millisecond,
+		ExitCode:  2,
+		Err:       &myecs.RemoteExitError{Code: 2},
+	}
+
+	entry := auditEntry(session, "ap-northeast-1")
+	assert.Equal(t, started, entry.Time)
+	assert.Equal(t, "ap-northeast-1", entry.Region)
+	assert.Equal(t, "ecs-execute-command-1", entry.SessionID)
+	assert.Equal(t, 1.5, entry.Duration)
+	assert.Equal(t, 2, *entry.ExitCode)
+	assert.Empty(t, entry.Error)
+
+	session.ExitCode, session.Err = -1, myecs.ErrPluginMissing
+	entry = auditEntry(session, "ap-northeast-1")
+	assert.Nil(t, entry.ExitCode)
+	assert.Equal(t, myecs.ErrPluginMissing.Error(), entry.Error)
+}
+
+func TestEnableAudit(t *testing.T) {
+	saved, savedIdentity := appConfig, callerIdentity
+	t.Cleanup(func() { appConfig, callerIdentity = saved, savedIdentity })
+
+	calls := 0
+	callerIdentity = func(ctx context.Context, cfg aws.Config) (string, string, error) {
+		calls++
+		return "arn:aws:iam::123456789012:user/alice", "123456789012", nil
+	}
+	appConfig = config.Default()
+	appConfig.Audit.Path = filepath.Join(t.TempDir(), "audit.jsonl")
+
+	ecsClient := &myecs.ECSResource{Region: "ap-northeast-1"}
+	enableAudit(ecsClient, newAWSIdentity(aws.Config{}))
+	ecsClient.OnSession(myecs.Session{Cluster: "prod", ExitCode: 0})
+	ecsClient.OnSession(myecs.Session{Cluster: "stg", ExitCode: -1, Detached: true})
+
+	entries, err := audit.NewLog(appConfig.Audit.Path).Read()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "arn:aws:iam::123456789012:user/alice", entries[1].Caller)
+	assert.Equal(t, "123456789012", entries[0].Account)
+	assert.Equal(t, "ap-northeast-1", entries[0].Region)
+
+	appConfig.Audit.Enabled = false
+	disabled := &myecs.ECSResource{}
+	enableAudit(disabled, nil)
+	assert.Nil(t, disabled.OnSession)
+}
+
+func TestAuditEntryRedactsSecrets(t *testing.T) {
+	saved := secretRedactor
+	t.Cleanup(func() { secretRedactor = saved })
+
+	var err error
+	secretRedactor, err = newRedactor(config.Default().Redact)
+	assert.NoError(t, err)
+	registerSecrets(itemsToResources([]selectableItem{{
+		cluster:   myecs.ECSCluster{ClusterName: "prod"},
+		service:   myecs.ECSService{ServiceName: "api"},
+		task:      myecs.ECSTask{TaskArn: "task1"},
+		container: myecs.ECSContainer{ContainerName: "app", SecretNames: []string{"STRIPE_KEY"}},
+	}}))
+
+	entry := auditEntry(myecs.Session{Command: "export STRIPE_KEY=sk_live_123; exec bash", ExitCode: -1}, "ap-northeast-1")
+	assert.Equal(t, "export STRIPE_KEY=[REDACTED]; exec bash", entry.Command)
+
+	secretRedactor, err = newRedactor(config.RedactConfig{})
+	assert.NoError(t, err)
+	assert.Nil(t, secretRedactor)
+}
+
+func TestAuditEntryMasksEnvValues(t *testing.T) {
+	saved := secretRedactor
+	t.Cleanup(func() { secretRedactor = saved })
+	secretRedactor = nil
+
+	for _, windows := range []bool{false, true} {
+		spec := myecs.LoginSpec{
+			Shell:   "bash",
+			Command: "env",
+			Env:     []string{"DB_PASSWORD=hunter2", "FOO=bar baz"},
+			Windows: windows,
+		}
+		masked := spec
+		masked.Env = []string{"DB_PASSWORD=[REDACTED]", "FOO=[REDACTED]"}
+
+		entry := auditEntry(myecs.Session{Command: commandLine(spec), ExitCode: -1}, "ap-northeast-1")
+		assert.Equal(t, masked.CommandLine(), entry.Command)
+		assert.NotContains(t, entry.Command, "hunter2")
+	}
+}
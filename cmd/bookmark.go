@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedipunkz/miniecs/internal/pkg/config"
+	"github.com/olekukonko/tablewriter"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var bookmarkSetFlags struct {
+	region  string
+	shell   string
+	command string
+	session sessionFlags
+	force   bool
+}
+
+var bookmarkCmd = &cobra.Command{
+	Use:   "bookmark",
+	Short: "manage named targets for login @name",
+	Long: `Manage bookmarks, named targets stored in the config file.
+
+A bookmark names a cluster, service and container. "miniecs login @name"
+logs in to the container in a running task of the service, so bookmarks
+stay valid across deployments.`,
+}
+
+var bookmarkAddCmd = &cobra.Command{
+	Use:   "add name cluster/service/container",
+	Short: "add a bookmark",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runBookmarkAddCmd,
+}
+
+var bookmarkListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "list bookmarks",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return renderBookmarks(os.Stdout, appConfig.Bookmarks)
+	},
+}
+
+var bookmarkRemoveCmd = &cobra.Command{
+	Use:     "remove name",
+	Aliases: []string{"rm"},
+	Short:   "remove a bookmark",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return config.RemoveBookmark(args[0])
+	},
+}
+
+func runBookmarkAddCmd(cmd *cobra.Command, args []string) error {
+	name, path := args[0], args[1]
+	bookmark, err := newBookmark(name, path)
+	if err != nil {
+		return err
+	}
+	if _, ok := appConfig.Bookmarks[name]; ok && !bookmarkSetFlags.force {
+		return fmt.Errorf("bookmark %q already exists, pass --force to replace it", name)
+	}
+
+	if err := config.SaveBookmark(name, bookmark); err != nil {
+		return err
+	}
+	log.Infof("Bookmark %s saved to %s, log in with: miniecs login @%s", name, configPathForHelp(), name)
+	return nil
+}
+
+func newBookmark(name, path string) (config.Bookmark, error) {
+	if name == "" || strings.ContainsAny(name, "@/ ") {
+		return config.Bookmark{}, fmt.Errorf("invalid bookmark name %q, it must not contain @, / or spaces", name)
+	}
+
+	var target targetFlags
+	if err := target.applyPath(path); err != nil {
+		return config.Bookmark{}, err
+	}
+	if target.cluster == "" {
+		return config.Bookmark{}, fmt.Errorf("invalid target %q, a bookmark needs at least a cluster", path)
+	}
+
+	return config.Bookmark{
+		Region:    bookmarkSetFlags.region,
+		Cluster:   target.cluster,
+		Service:   target.service,
+		Container: target.container,
+		Shell:     bookmarkSetFlags.shell,
+		User:      bookmarkSetFlags.session.user,
+		WorkDir:   bookmarkSetFlags.session.workdir,
+		Command:   bookmarkSetFlags.command,
+	}, nil
+}
+
+// bookmarkName returns name for a target argument of the form "@name".
+func bookmarkName(arg string) (string, bool) {
+	return strings.CutPrefix(arg, "@")
+}
+
+func lookupBookmark(name string) (config.Bookmark, error) {
+	bookmark, ok := appConfig.Bookmarks[name]
+	if !ok {
+		names := make([]string, 0, len(appConfig.Bookmarks))
+		for known := range appConfig.Bookmarks {
+			names = append(names, "@"+known)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return config.Bookmark{}, fmt.Errorf("no bookmark named %q, add one with `miniecs bookmark add`", name)
+		}
+		return config.Bookmark{}, fmt.Errorf("no bookmark named %q, known bookmarks: %s", name, strings.Join(names, ", "))
+	}
+	return bookmark, nil
+}
+
+// applyBookmark points t at the targets of bookmark. A bookmark naming a
+// service and container logs in to a running task chosen at login time,
+// unless --task names one, others leave the choice to the picker.
+func (t *targetFlags) applyBookmark(bookmark config.Bookmark) {
+	t.cluster = bookmark.Cluster
+	t.service = bookmark.Service
+	t.container = bookmark.Container
+	t.pickRunning = t.task == "" && bookmark.Service != "" && bookmark.Container != ""
+}
+
+// applySessionBookmark fills the session settings not given as flags from
+// bookmark.
+func applySessionBookmark(region *string, session *sessionFlags, bookmark config.Bookmark) {
+	if *region == "" {
+		*region = bookmark.Region
+	}
+	if session.user == "" {
+		session.user = bookmark.User
+	}
+	if session.workdir == "" {
+		session.workdir = bookmark.WorkDir
+	}
+}
+
+func renderBookmarks(w io.Writer, bookmarks map[string]config.Bookmark) error {
+	names := make([]string, 0, len(bookmarks))
+	for name := range bookmarks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	table := tablewriter.NewTable(w,
+		tablewriter.WithHeader([]string{
+			"Name",
+			"Region",
+			"Target",
+			"Shell",
+			"User",
+			"Workdir",
+			"Command"}))
+	for _, name := range names {
+		bookmark := bookmarks[name]
+		if err := table.Append([]string{
+			"@" + name,
+			bookmark.Region,
+			strings.TrimRight(strings.Join([]string{bookmark.Cluster, bookmark.Service, bookmark.Container}, "/"), "/"),
+			bookmark.Shell,
+			bookmark.User,
+			bookmark.WorkDir,
+			bookmark.Command,
+		}); err != nil {
+			return err
+		}
+	}
+	return table.Render()
+}
+
+func init() {
+	rootCmd.AddCommand(bookmarkCmd)
+	bookmarkCmd.AddCommand(bookmarkAddCmd, bookmarkListCmd, bookmarkRemoveCmd)
+
+	bookmarkAddCmd.Flags().StringVarP(
+		&bookmarkSetFlags.region, "region", "", "", "Region of the target")
+	bookmarkAddCmd.Flags().StringVarP(
+		&bookmarkSetFlags.shell, "shell", "", "", "Login Shell")
+	bookmarkAddCmd.Flags().StringVarP(
+		&bookmarkSetFlags.command, "command", "c", "", "Command declared with a miniecs.commands.<name> docker label")
+	bookmarkAddCmd.Flags().StringVarP(
+		&bookmarkSetFlags.session.user, "user", "u", "", "Run as this user instead of the image default")
+	bookmarkAddCmd.Flags().StringVarP(
+		&bookmarkSetFlags.session.workdir, "workdir", "w", "", "Start in this working directory")
+	bookmarkAddCmd.Flags().BoolVarP(
+		&bookmarkSetFlags.force, "force", "f", false, "Replace an existing bookmark")
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/jedipunkz/miniecs/internal/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBookmark(t *testing.T) {
+	saved := bookmarkSetFlags
+	t.Cleanup(func() { bookmarkSetFlags = saved })
+	bookmarkSetFlags.shell = "bash"
+	bookmarkSetFlags.session.user = "app"
+
+	bookmark, err := newBookmark("api-prod", "prod/api/app")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Bookmark{Cluster: "prod", Service: "api", Container: "app", Shell: "bash", User: "app"}, bookmark)
+
+	_, err = newBookmark("@api", "prod/api/app")
+	assert.ErrorContains(t, err, "invalid bookmark name")
+	_, err = newBookmark("api", "/api/app")
+	assert.ErrorContains(t, err, "needs at least a cluster")
+	_, err = newBookmark("api", "a/b/c/d")
+	assert.Error(t, err)
+}
+
+func TestRunBookmarkAddCmd(t *testing.T) {
+	savedConfig, savedFlags := appConfig, bookmarkSetFlags
+	t.Cleanup(func() { appConfig, bookmarkSetFlags = savedConfig, savedFlags })
+	t.Setenv(config.EnvPath, filepath.Join(t.TempDir(), "config.yaml"))
+	appConfig = config.Default()
+
+	assert.NoError(t, runBookmarkAddCmd(bookmarkAddCmd, []string{"api-prod", "prod/api/app"}))
+
+	cfg, err := config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "api", cfg.Bookmarks["api-prod"].Service)
+
+	appConfig = cfg
+	assert.ErrorContains(t, runBookmarkAddCmd(bookmarkAddCmd, []string{"api-prod", "prod/worker"}), "--force")
+	bookmarkSetFlags.force = true
+	assert.NoError(t, runBookmarkAddCmd(bookmarkAddCmd, []string{"api-prod", "prod/worker"}))
+
+	cfg, err = config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "worker", cfg.Bookmarks["api-prod"].Service)
+}
+
+func TestLookupBookmark(t *testing.T) {
+	saved := appConfig
+	t.Cleanup(func() { appConfig = saved })
+	appConfig = config.Default()
+
+	_, err := lookupBookmark("api")
+	assert.ErrorContains(t, err, "bookmark add")
+
+	appConfig.Bookmarks = map[string]config.Bookmark{
+		"worker": {Cluster: "prod", Service: "worker"},
+		"api":    {Cluster: "prod", Service: "api"},
+	}
+	bookmark, err := lookupBookmark("api")
+	assert.NoError(t, err)
+	assert.Equal(t, "api", bookmark.Service)
+
+	_, err = lookupBookmark("db")
+	assert.ErrorContains(t, err, "known bookmarks: @api, @worker")
+
+	name, ok := bookmarkName("@api")
+	assert.True(t, ok)
+	assert.Equal(t, "api", name)
+	_, ok = bookmarkName("prod/api")
+	assert.False(t, ok)
+}
+
+func TestApplyBookmark(t *testing.T) {
+	bookmark := config.Bookmark{Region: "ap-northeast-1", Cluster: "prod", Service: "api", Container: "app", User: "app", WorkDir: "/srv"}
+
+	target := targetFlags{}
+	target.applyBookmark(bookmark)
+	assert.Equal(t, targetFlags{cluster: "prod", service: "api", container: "app", pickRunning: true}, target)
+
+	// --task overrides the running task chosen at login time
+	target = targetFlags{task: "task1"}
+	target.applyBookmark(bookmark)
+	assert.Equal(t, targetFlags{cluster: "prod", service: "api", container: "app", task: "task1"}, target)
+
+	// without a service and container the picker chooses
+	target = targetFlags{}
+	target.applyBookmark(config.Bookmark{Cluster: "prod"})
+	assert.Equal(t, targetFlags{cluster: "prod"}, target)
+	target.applyBookmark(config.Bookmark{Cluster: "prod", Service: "api"})
+	assert.Equal(t, targetFlags{cluster: "prod", service: "api"}, target)
+
+	region := ""
+	session := sessionFlags{workdir: "/tmp"}
+	applySessionBookmark(&region, &session, bookmark)
+	assert.Equal(t, "ap-northeast-1", region)
+	assert.Equal(t, "app", session.user)
+	assert.Equal(t, "/tmp", session.workdir)
+}
+
+func TestRenderBookmarks(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, renderBookmarks(&buf, map[string]config.Bookmark{
+		"api": {Region: "ap-northeast-1", Cluster: "prod", Service: "api", Container: "app", Shell: "bash"},
+		"db":  {Cluster: "prod"},
+	}))
+	assert.Contains(t, buf.String(), "@api")
+	assert.Contains(t, buf.String(), "prod/api/app")
+	assert.Contains(t, buf.String(), "@db")
+}
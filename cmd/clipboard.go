@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// clipboardCommands are tried in order until one is installed.
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"clip.exe"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+}
+
+// copyToClipboard copies text with the first clipboard command found.
+func copyToClipboard(text string) error {
+	var names []string
+	for _, command := range clipboardCommands {
+		names = append(names, command[0])
+		path, err := exec.LookPath(command[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, command[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s failed: %w", command[0], err)
+		}
+		return nil
+	}
+	return fmt.Errorf("none of %s is installed", strings.Join(names, ", "))
+}
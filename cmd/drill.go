@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/picker"
+)
+
+// drillLevel is a level of the drill-down picker above containers.
+type drillLevel struct {
+	name   string
+	plural string
+	key    func(selectableItem) string
+	label  func(selectableItem) string
+}
+
+var drillLevels = []drillLevel{
+	{
+		name:   "cluster",
+		plural: "Clusters",
+		key:    func(item selectableItem) string { return item.cluster.ClusterName },
+		label:  func(item selectableItem) string { return item.cluster.ClusterName },
+	},
+	{
+		name:   "service",
+		plural: "Services",
+		key:    func(item selectableItem) string { return item.service.ServiceName },
+		label:  func(item selectableItem) string { return item.service.ServiceName },
+	},
+	{
+		name:   "task",
+		plural: "Tasks",
+		key:    func(item selectableItem) string { return item.task.TaskArn },
+		label: func(item selectableItem) string {
+			return fmt.Sprintf("%s %s", taskID(item.task.TaskArn), item.task.LastStatus)
+		},
+	},
+}
+
+// drillSelectableItems lets the user choose a cluster, then a service, a
+// task and finally containers of items. Levels with a single choice are
+// skipped, and Esc goes back to the previous level.
+func drillSelectableItems(p picker.Picker, items []selectableItem, showSidecars bool) ([]myecs.ECSResource, error) {
+	chosen := make([]string, len(drillLevels))
+	asked := make([]bool, len(drillLevels)+1)
+
+	level := 0
+	for {
+		candidates := itemsUnder(items, chosen[:level])
+
+		var resources []myecs.ECSResource
+		var err error
+		if level == len(drillLevels) {
+			visible := candidates
+			if primary := withoutSidecars(candidates); !showSidecars && len(primary) > 0 {
+				visible = primary
+			}
+			if len(visible) == 1 {
+				return []myecs.ECSResource{visible[0].toResource()}, nil
+			}
+			asked[level] = true
+			resources, _, err = pickSelectableItems(p, candidates, showSidecars, false)
+		} else {
+			entries := distinctByLevel(candidates, drillLevels[level])
+			if len(entries) == 1 {
+				chosen[level] = drillLevels[level].key(entries[0])
+				asked[level] = false
+				level++
+				continue
+			}
+			var selected []int
+			current := drillLevels[level]
+			selected, err = p.Pick(drillLabels(entries, current), picker.Options{
+				Header: drillHeader(chosen[:level], level),
+				Preview: func(i int) string {
+					return drillPreview(itemsWithKey(candidates, current, current.key(entries[i])), level)
+				},
+			})
+			if err == nil {
+				chosen[level] = current.key(entries[selected[0]])
+				asked[level] = true
+				level++
+				continue
+			}
+		}
+
+		if errors.Is(err, picker.ErrAbort) {
+			if previous, ok := previousDrillLevel(asked, level); ok {
+				level = previous
+				continue
+			}
+		}
+		return resources, err
+	}
+}
+
+// itemsUnder returns the items below the keys chosen on the levels above.
+func itemsUnder(items []selectableItem, chosen []string) []selectableItem {
+	for level, key := range chosen {
+		items = itemsWithKey(items, drillLevels[level], key)
+	}
+	return items
+}
+
+func itemsWithKey(items []selectableItem, level drillLevel, key string) []selectableItem {
+	var filtered []selectableItem
+	for _, item := range items {
+		if level.key(item) == key {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// distinctByLevel returns the first item of every key of level in items.
+func distinctByLevel(items []selectableItem, level drillLevel) []selectableItem {
+	seen := map[string]bool{}
+	var entries []selectableItem
+	for _, item := range items {
+		if key := level.key(item); !seen[key] {
+			seen[key] = true
+			entries = append(entries, item)
+		}
+	}
+	return entries
+}
+
+func drillLabels(entries []selectableItem, level drillLevel) []string {
+	labels := make([]string, len(entries))
+	for i, entry := range entries {
+		labels[i] = level.label(entry)
+	}
+	return labels
+}
+
+// drillHeader shows the path chosen so far above the list.
+func drillHeader(chosen []string, level int) string {
+	path := make([]string, len(chosen))
+	for i, key := range chosen {
+		path[i] = key
+		if drillLevels[i].name == "task" {
+			path[i] = taskID(key)
+		}
+	}
+	header := "Select a " + drillLevels[level].name
+	if len(path) > 0 {
+		header += " in " + strings.Join(path, "/")
+	}
+	if level > 0 {
+		header += " (Esc: back)"
+	}
+	return header
+}
+
+// drillPreview summarises items, the items below an entry of level.
+func drillPreview(items []selectableItem, level int) string {
+	entry := items[0]
+
+	var preview strings.Builder
+	preview.WriteString(productionBanner(entry.cluster.ClusterName))
+	fmt.Fprintf(&preview, "Cluster: %s\n", entry.cluster.ClusterName)
+	if level >= 1 {
+		fmt.Fprintf(&preview, "Service: %s\n", entry.service.ServiceName)
+	}
+	if level >= 2 {
+		fmt.Fprintf(&preview, "Task: %s\nStatus: %s\n", taskID(entry.task.TaskArn), entry.task.LastStatus)
+	}
+	for _, below := range drillLevels[level+1:] {
+		fmt.Fprintf(&preview, "%s: %d\n", below.plural, len(distinctByLevel(items, below)))
+	}
+	if level == len(drillLevels)-1 {
+		var names []string
+		for _, item := range items {
+			names = append(names, item.container.ContainerName)
+		}
+		fmt.Fprintf(&preview, "Containers: %s\n", strings.Join(names, ", "))
+	} else {
+		fmt.Fprintf(&preview, "Containers: %d\n", len(items))
+	}
+	return preview.String()
+}
+
+// previousDrillLevel returns the closest level above level the user was
+// asked about.
+func previousDrillLevel(asked []bool, level int) (int, bool) {
+	for previous := level - 1; previous >= 0; previous-- {
+		if asked[previous] {
+			return previous, true
+		}
+	}
+	return 0, false
+}
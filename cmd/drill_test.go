@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"testing"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/picker"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePicker answers with choices in turn, -1 for Esc, and records the
+// headers it was shown, "containers" for the multi-select container list.
+type fakePicker struct {
+	t       *testing.T
+	choices []int
+	headers []string
+}
+
+func (p *fakePicker) Pick(labels []string, opts picker.Options) ([]int, error) {
+	header := opts.Header
+	if opts.Multi {
+		header = "containers"
+	}
+	p.headers = append(p.headers, header)
+	if len(p.choices) == 0 {
+		p.t.Fatalf("unexpected picker %q", header)
+	}
+	choice := p.choices[0]
+	p.choices = p.choices[1:]
+	if choice < 0 {
+		return nil, picker.ErrAbort
+	}
+	return []int{choice}, nil
+}
+
+func drilledTarget(resources []myecs.ECSResource) string {
+	cluster := resources[0].Clusters[0]
+	service := cluster.Services[0]
+	return cluster.ClusterName + "/" + service.ServiceName + "/" + service.Tasks[0].Containers[0].ContainerName
+}
+
+func TestDrillSelectableItems(t *testing.T) {
+	items := testSelectableItems()[:4]
+
+	t.Run("single choices are skipped", func(t *testing.T) {
+		p := &fakePicker{t: t, choices: []int{0, 0}}
+		resources, err := drillSelectableItems(p, items, false)
+		assert.NoError(t, err)
+		// task1 is the only task of prod/api, and envoy a sidecar
+		assert.Equal(t, "prod/api/app", drilledTarget(resources))
+		assert.Equal(t, []string{"Select a cluster", "Select a service in prod (Esc: back)"}, p.headers)
+	})
+
+	t.Run("esc goes back a level", func(t *testing.T) {
+		p := &fakePicker{t: t, choices: []int{0, -1, 1}}
+		resources, err := drillSelectableItems(p, items, false)
+		assert.NoError(t, err)
+		assert.Equal(t, "stg/api/app", drilledTarget(resources))
+		assert.Len(t, p.headers, 3)
+	})
+
+	t.Run("esc on the first level aborts", func(t *testing.T) {
+		_, err := drillSelectableItems(&fakePicker{t: t, choices: []int{-1}}, items, false)
+		assert.ErrorIs(t, err, picker.ErrAbort)
+	})
+
+	t.Run("containers of a task", func(t *testing.T) {
+		p := &fakePicker{t: t, choices: []int{0, 0, 1}}
+		resources, err := drillSelectableItems(p, items, true)
+		assert.NoError(t, err)
+		assert.Equal(t, "prod/api/envoy", drilledTarget(resources))
+		assert.Equal(t, "containers", p.headers[2])
+	})
+}
+
+func TestDrillPreview(t *testing.T) {
+	items := testSelectableItems()[:4]
+
+	preview := drillPreview(itemsWithKey(items, drillLevels[0], "prod"), 0)
+	assert.Contains(t, preview, "Cluster: prod\n")
+	assert.Contains(t, preview, "Services: 2\nTasks: 2\nContainers: 3\n")
+
+	preview = drillPreview(itemsWithKey(items, drillLevels[2], items[0].task.TaskArn), 2)
+	assert.Contains(t, preview, "Task: task1\n")
+	assert.Contains(t, preview, "Containers: app, envoy\n")
+}
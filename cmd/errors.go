@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"errors"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/picker"
+	"github.com/jedipunkz/miniecs/internal/pkg/policy"
+)
+
+const (
+	exitOK             = 0
+	exitError          = 1
+	exitNoResources    = 3
+	exitExecNotEnabled = 4
+	exitPluginMissing  = 5
+	exitAccessDenied   = 6
+	exitTaskGone       = 7
+	exitPolicyDenied   = 8
+	exitCancelled      = 130
+)
+
+// isRemoteExit reports whether err only carries the exit status of the
+// command run in the container, which has already printed its own output.
+func isRemoteExit(err error) bool {
+	var remoteErr *myecs.RemoteExitError
+	return errors.As(err, &remoteErr)
+}
+
+func exitCode(err error) int {
+	var remoteErr *myecs.RemoteExitError
+	switch {
+	case errors.As(err, &remoteErr):
+		return remoteErr.Code
+	case err == nil:
+		return exitOK
+	case errors.Is(err, myecs.ErrNoResources):
+		return exitNoResources
+	case errors.Is(err, myecs.ErrExecNotEnabled):
+		return exitExecNotEnabled
+	case errors.Is(err, myecs.ErrPluginMissing):
+		return exitPluginMissing
+	case errors.Is(err, myecs.ErrAccessDenied):
+		return exitAccessDenied
+	case errors.Is(err, myecs.ErrTaskGone):
+		return exitTaskGone
+	case errors.Is(err, policy.ErrDenied):
+		return exitPolicyDenied
+	case errors.Is(err, picker.ErrAbort):
+		return exitCancelled
+	}
+	return exitError
+}
+
+// hint returns the remediation for err printed below the error message.
+func hint(err error) string {
+	if errors.Is(err, policy.ErrDenied) {
+		return policyHint()
+	}
+	return myecs.Hint(err)
+}
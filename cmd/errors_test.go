@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/policy"
+	"github.com/ktr0731/go-fuzzyfinder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "nil", err: nil, expected: exitOK},
+		{name: "generic", err: assert.AnError, expected: exitError},
+		{name: "no resources", err: myecs.ErrNoResources, expected: exitNoResources},
+		{name: "exec not enabled", err: &myecs.Error{Kind: myecs.ErrExecNotEnabled}, expected: exitExecNotEnabled},
+		{name: "plugin missing", err: fmt.Errorf("wrapped: %w", myecs.ErrPluginMissing), expected: exitPluginMissing},
+		{name: "access denied", err: &myecs.Error{Kind: myecs.ErrAccessDenied, Err: assert.AnError}, expected: exitAccessDenied},
+		{name: "task gone", err: &myecs.Error{Kind: myecs.ErrTaskGone}, expected: exitTaskGone},
+		{name: "policy denied", err: &policy.DeniedError{}, expected: exitPolicyDenied},
+		{name: "cancelled", err: fuzzyfinder.ErrAbort, expected: exitCancelled},
+		{name: "remote exit", err: &myecs.RemoteExitError{Code: 42}, expected: 42},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, exitCode(tt.err))
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+type execFlags struct {
+	region      string
+	target      targetFlags
+	session     sessionFlags
+	stdin       bool
+	allMatching bool
+	parallel    int
+	policyCheck bool
+}
+
+var execSetFlags execFlags
+
+var execCmd = &cobra.Command{
+	Use:   "exec [cluster/service/container | @bookmark] -- command [args...]",
+	Short: "run a command in a container",
+	Long: `Run a one-shot command in a container and exit with its exit status.
+
+The container is chosen from --cluster, --service, --task and --container,
+from a cluster/service/container path, or with the picker when several
+containers match. Selecting several containers in the picker, or passing
+--all-matching, runs the command on each of them in parallel.
+
+Commands are checked against the policy file before they are run, and
+--policy-check prints the decision without running anything.`,
+	Annotations: map[string]string{annotationMutating: "true"},
+	RunE:        runExecCmd,
+}
+
+func runExecCmd(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	stdinForwarded = execSetFlags.stdin
+
+	targetArgs, command, err := splitExecArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	target := execSetFlags.target
+	if name, ok := bookmarkName(firstArg(targetArgs)); ok {
+		bookmark, err := lookupBookmark(name)
+		if err != nil {
+			return err
+		}
+		target.applyBookmark(bookmark)
+		applySessionBookmark(&execSetFlags.region, &execSetFlags.session, bookmark)
+	} else if len(targetArgs) > 0 {
+		if err := target.applyPath(targetArgs[0]); err != nil {
+			return err
+		}
+	}
+
+	if execSetFlags.allMatching && target.pick != "" {
+		return fmt.Errorf("--pick cannot be combined with --all-matching")
+	}
+
+	region, err := resolveRegion(execSetFlags.region)
+	if err != nil {
+		return err
+	}
+
+	ecsClient, err := initializeECSClient(ctx, region)
+	if err != nil {
+		return err
+	}
+
+	var selectedResources []myecs.ECSResource
+	if execSetFlags.allMatching {
+		items, err := matchTargets(ctx, ecsClient, target)
+		if err != nil {
+			return err
+		}
+		selectedResources = itemsToResources(target.primaryItems(items))
+	} else {
+		selectedResources, err = selectTargets(ctx, ecsClient, target)
+		if err != nil {
+			return err
+		}
+	}
+
+	registerSecrets(selectedResources)
+
+	execPolicy, err := loadPolicy()
+	if err != nil {
+		return err
+	}
+	if execSetFlags.policyCheck {
+		return renderPolicyCheck(os.Stdout, execPolicy, selectedResources, command)
+	}
+	if err := checkPolicy(execPolicy, selectedResources, command); err != nil {
+		return err
+	}
+
+	if err := confirmProduction(selectedResources); err != nil {
+		return err
+	}
+
+	if len(selectedResources) > 1 {
+		return executeFanout(ecsClient, selectedResources, command, execSetFlags.parallel)
+	}
+	return executeCommand(ecsClient, selectedResources[0], command)
+}
+
+// splitExecArgs separates the optional target path from the command given
+// after "--".
+func splitExecArgs(cmd *cobra.Command, args []string) (targetArgs, command []string, err error) {
+	dash := cmd.ArgsLenAtDash()
+	if dash < 0 {
+		return nil, nil, fmt.Errorf("no command given, use: %s", cmd.Use)
+	}
+	targetArgs, command = args[:dash], args[dash:]
+	if len(targetArgs) > 1 {
+		return nil, nil, fmt.Errorf("only one target may be given before --, got %d", len(targetArgs))
+	}
+	if len(command) == 0 {
+		return nil, nil, fmt.Errorf("no command given after --")
+	}
+	return targetArgs, command, nil
+}
+
+func executeCommand(ecsClient *myecs.ECSResource, resource myecs.ECSResource, command []string) error {
+	commandInput, err := createExecCommandInput(resource, command)
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"cluster":   *commandInput.Cluster,
+		"task":      *commandInput.Task,
+		"container": *commandInput.Container,
+		"command":   *commandInput.Command,
+	}).Debug("ECS Execute Command with These Parameters")
+
+	err = ecsClient.ExecuteCommandWithExitCode(commandInput, execOptions(resource))
+	return withService(err, resource)
+}
+
+// createExecCommandInput runs command as the user and in the directory
+// chosen by flags or docker labels.
+func createExecCommandInput(resource myecs.ECSResource, command []string) (ecs.ExecuteCommandInput, error) {
+	spec := sessionSpec(resource, execSetFlags.session)
+	if err := spec.Validate(); err != nil {
+		return ecs.ExecuteCommandInput{}, err
+	}
+	spec.Command = remoteCommand(resource, command)
+
+	commandInput := createExecuteCommandInput(resource)
+	commandInput.Command = aws.String(commandLine(spec))
+	return commandInput, nil
+}
+
+func execOptions(resource myecs.ECSResource) myecs.ExecOptions {
+	opts := myecs.ExecOptions{
+		TTY:     term.IsTerminal(int(os.Stdout.Fd())),
+		Windows: isWindows(resource),
+	}
+	if execSetFlags.stdin {
+		opts.Stdin = os.Stdin
+	}
+	return opts
+}
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+	execCmd.Flags().StringVarP(
+		&execSetFlags.region, "region", "", "", "Region Name (defaults to the region of --context)")
+	addTargetFlags(execCmd, &execSetFlags.target)
+	addSessionFlags(execCmd, &execSetFlags.session)
+	execCmd.Flags().BoolVarP(
+		&execSetFlags.stdin, "stdin", "i", false, "Pass stdin to the command")
+	execCmd.Flags().BoolVarP(
+		&execSetFlags.allMatching, "all-matching", "", false, "Run on every matching container")
+	execCmd.Flags().IntVarP(
+		&execSetFlags.parallel, "parallel", "", 5, "Maximum number of concurrent sessions")
+	execCmd.Flags().BoolVarP(
+		&execSetFlags.policyCheck, "policy-check", "", false, "Print the policy decision without running the command")
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitExecArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		targetArgs []string
+		command    []string
+		wantErr    bool
+	}{
+		{
+			name:       "target and command",
+			args:       []string{"prod/api/app", "--", "ls", "-la"},
+			targetArgs: []string{"prod/api/app"},
+			command:    []string{"ls", "-la"},
+		},
+		{
+			name:       "command only",
+			args:       []string{"--", "env"},
+			targetArgs: []string{},
+			command:    []string{"env"},
+		},
+		{
+			name:    "missing dash",
+			args:    []string{"prod/api/app"},
+			wantErr: true,
+		},
+		{
+			name:    "missing command",
+			args:    []string{"prod/api/app", "--"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "exec", Run: func(*cobra.Command, []string) {}}
+			assert.NoError(t, cmd.ParseFlags(tt.args))
+
+			targetArgs, command, err := splitExecArgs(cmd, cmd.Flags().Args())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.targetArgs, targetArgs)
+			assert.Equal(t, tt.command, command)
+		})
+	}
+}
+
+func TestCreateExecCommandInput(t *testing.T) {
+	defer func(session sessionFlags) { execSetFlags.session = session }(execSetFlags.session)
+
+	item := testSelectableItems()[0]
+	item.container.User = "root"
+	resource := item.toResource()
+
+	execSetFlags.session = sessionFlags{user: "app", workdir: "/srv/app"}
+	input, err := createExecCommandInput(resource, []string{"ls", "-la"})
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", *input.Cluster)
+	assert.Contains(t, *input.Command, "runuser -u app")
+	assert.Contains(t, *input.Command, "cd /srv/app && exec ls -la")
+
+	windows := testSelectableItems()[0]
+	windows.task.OSFamily = "WINDOWS_SERVER_2022_CORE"
+	_, err = createExecCommandInput(windows.toResource(), []string{"dir"})
+	assert.Error(t, err)
+}
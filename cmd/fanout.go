@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/olekukonko/tablewriter"
+)
+
+type fanoutResult struct {
+	target   string
+	err      error
+	duration time.Duration
+}
+
+// executeFanout runs command on every resource with at most parallel
+// sessions at a time, prefixing each output line with its target.
+func executeFanout(ecsClient *myecs.ECSResource, resources []myecs.ECSResource, command []string, parallel int) error {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallel)
+	results := make([]fanoutResult, len(resources))
+
+	for i, resource := range resources {
+		wg.Add(1)
+		go func(i int, resource myecs.ECSResource) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			target := targetLabel(resource)
+			stdout := newPrefixWriter(&mu, os.Stdout, target)
+			stderr := newPrefixWriter(&mu, os.Stderr, target)
+			stdout.redact, stderr.redact = secretRedactor.String, secretRedactor.String
+
+			started := time.Now()
+			commandInput, err := createExecCommandInput(resource, command)
+			if err == nil {
+				err = ecsClient.ExecuteCommandWithExitCode(commandInput, myecs.ExecOptions{
+					Windows: isWindows(resource),
+					Stdout:  stdout,
+					Stderr:  stderr,
+				})
+			}
+			stdout.Flush()
+			stderr.Flush()
+
+			results[i] = fanoutResult{
+				target:   target,
+				err:      withService(err, resource),
+				duration: time.Since(started),
+			}
+		}(i, resource)
+	}
+	wg.Wait()
+
+	if err := renderFanoutSummary(os.Stderr, results); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("command failed on %d of %d targets", failed, len(results))
+	}
+	return nil
+}
+
+func renderFanoutSummary(w io.Writer, results []fanoutResult) error {
+	table := tablewriter.NewTable(w,
+		tablewriter.WithHeader([]string{
+			"Target",
+			"Result",
+			"Exit Code",
+			"Duration",
+			"Error"}))
+	for _, result := range results {
+		status, code, message := "ok", "0", ""
+		if result.err != nil {
+			status, code, message = "failed", strconv.Itoa(exitCode(result.err)), result.err.Error()
+		}
+		var remoteErr *myecs.RemoteExitError
+		if errors.As(result.err, &remoteErr) {
+			message = ""
+		}
+		if err := table.Append([]string{
+			result.target,
+			status,
+			code,
+			result.duration.Round(time.Millisecond).String(),
+			message,
+		}); err != nil {
+			return err
+		}
+	}
+	return table.Render()
+}
+
+// targetLabel names a selected container as cluster/service/task.
+func targetLabel(resource myecs.ECSResource) string {
+	cluster := resource.Clusters[0]
+	service := cluster.Services[0]
+	return fmt.Sprintf("%s/%s/%s", cluster.ClusterName, service.ServiceName, taskID(service.Tasks[0].TaskArn))
+}
+
+// prefixWriter writes complete lines to w, each preceded by prefix. The
+// mutex is shared between writers so that lines of concurrent sessions
+// never interleave.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+	// redact filters every line when set.
+	redact func(string) string
+}
+
+func newPrefixWriter(mu *sync.Mutex, w io.Writer, prefix string) *prefixWriter {
+	return &prefixWriter{mu: mu, w: w, prefix: "[" + prefix + "] "}
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		idx := bytes.IndexByte(p.buf, '\n')
+		if idx < 0 {
+			return len(b), nil
+		}
+		if err := p.writeLine(p.buf[:idx]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[idx+1:]
+	}
+}
+
+func (p *prefixWriter) Flush() {
+	if len(p.buf) > 0 {
+		_ = p.writeLine(p.buf)
+		p.buf = nil
+	}
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if n := len(line); n > 0 && line[n-1] == '\r' {
+		line = line[:n-1]
+	}
+	if p.redact != nil {
+		line = []byte(p.redact(string(line)))
+	}
+	_, err := fmt.Fprintf(p.w, "%s%s\n", p.prefix, line)
+	return err
+}
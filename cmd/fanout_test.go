@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var mu sync.Mutex
+	var out bytes.Buffer
+	w := newPrefixWriter(&mu, &out, "prod/api/task1")
+
+	_, err := w.Write([]byte("first\r\nsec"))
+	assert.NoError(t, err)
+	_, err = w.Write([]byte("ond\nlast"))
+	assert.NoError(t, err)
+	w.Flush()
+
+	assert.Equal(t,
+		"[prod/api/task1] first\n[prod/api/task1] second\n[prod/api/task1] last\n",
+		out.String())
+}
+
+func TestTargetLabel(t *testing.T) {
+	resource := testSelectableItems()[0].toResource()
+	assert.Equal(t, "prod/api/task1", targetLabel(resource))
+}
+
+func TestRenderFanoutSummary(t *testing.T) {
+	var out bytes.Buffer
+	err := renderFanoutSummary(&out, []fanoutResult{
+		{target: "prod/api/task1", duration: time.Second},
+		{target: "prod/api/task2", err: &myecs.RemoteExitError{Code: 2}},
+		{target: "prod/api/task3", err: &myecs.Error{Kind: myecs.ErrTaskGone}},
+	})
+	assert.NoError(t, err)
+
+	summary := out.String()
+	assert.Contains(t, summary, "prod/api/task1")
+	assert.Contains(t, summary, "failed")
+	assert.Contains(t, summary, "task is no longer running")
+}
+
+func TestPrefixWriterRedact(t *testing.T) {
+	var mu sync.Mutex
+	var out bytes.Buffer
+	w := newPrefixWriter(&mu, &out, "prod/api/task1")
+	w.redact = func(s string) string { return strings.ReplaceAll(s, "s3cr3t", "[REDACTED]") }
+
+	_, err := w.Write([]byte("TOKEN=s3c"))
+	assert.NoError(t, err)
+	_, err = w.Write([]byte("r3t\n"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "[prod/api/task1] TOKEN=[REDACTED]\n", out.String())
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/config"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// annotationMutating marks subcommands that change resources, which are
+// refused in readonly contexts.
+const annotationMutating = "miniecs/mutating"
+
+var guardSetFlags struct {
+	context string
+	yes     bool
+}
+
+// currentContext returns the context chosen with --context or
+// $MINIECS_CONTEXT.
+func currentContext() (string, config.ContextConfig, error) {
+	name := guardSetFlags.context
+	if name == "" {
+		name = os.Getenv("MINIECS_CONTEXT")
+	}
+	if name == "" {
+		return "", config.ContextConfig{}, nil
+	}
+	context, ok := appConfig.Contexts[name]
+	if !ok {
+		return "", config.ContextConfig{}, fmt.Errorf("unknown context %q, add it under contexts in %s", name, configPathForHelp())
+	}
+	return name, context, nil
+}
+
+// resolveRegion returns region, or the region of the current context when
+// region is empty.
+func resolveRegion(region string) (string, error) {
+	if region != "" {
+		return region, nil
+	}
+	name, context, err := currentContext()
+	if err != nil {
+		return "", err
+	}
+	if context.Region == "" {
+		if name != "" {
+			return "", fmt.Errorf("context %q has no region, pass --region", name)
+		}
+		return "", fmt.Errorf("required flag \"region\" not set")
+	}
+	return context.Region, nil
+}
+
+// checkReadonly refuses mutating subcommands in readonly contexts.
+func checkReadonly(cmd *cobra.Command) error {
+	if cmd.Annotations[annotationMutating] != "true" {
+		return nil
+	}
+	return refuseInReadonly(cmd.CommandPath())
+}
+
+// refuseInReadonly returns an error naming what when the current context is
+// readonly.
+func refuseInReadonly(what string) error {
+	name, context, err := currentContext()
+	if err != nil {
+		return err
+	}
+	if context.Readonly {
+		return fmt.Errorf("%s is not allowed in readonly context %q", what, name)
+	}
+	return nil
+}
+
+func isProduction(cluster string) bool {
+	if _, context, err := currentContext(); err == nil && context.Production {
+		return true
+	}
+	return appConfig.Production.Matches(cluster)
+}
+
+// confirmInput returns where the typed confirmation is read from. stdin may
+// carry data for the remote command, so the terminal is opened directly.
+var confirmInput = func() (io.ReadCloser, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open("/dev/tty")
+}
+
+// confirmProduction asks to type the name of every production cluster among
+// resources before a session is started in them.
+func confirmProduction(resources []myecs.ECSResource) error {
+	if !appConfig.Production.Confirm || guardSetFlags.yes {
+		return nil
+	}
+
+	clusters := productionClusters(resources)
+	if len(clusters) == 0 {
+		return nil
+	}
+
+	in, err := confirmInput()
+	if err != nil {
+		return fmt.Errorf("production cluster %s needs confirmation but no terminal is available, pass --yes: %w", clusters[0], err)
+	}
+	defer in.Close()
+
+	reader := bufio.NewReader(in)
+	for _, cluster := range clusters {
+		fmt.Fprintf(os.Stderr, "\x1b[1;31m%s is a PRODUCTION cluster.\x1b[0m Type the cluster name to continue: ", cluster)
+		answer, err := reader.ReadString('\n')
+		if err != nil && answer == "" {
+			return fmt.Errorf("confirmation for %s aborted: %w", cluster, err)
+		}
+		if strings.TrimSpace(answer) != cluster {
+			return fmt.Errorf("confirmation for %s did not match, aborting", cluster)
+		}
+	}
+	return nil
+}
+
+// confirmYes asks question and succeeds only when it is answered with yes,
+// or when --yes was passed.
+func confirmYes(question string) error {
+	if guardSetFlags.yes {
+		return nil
+	}
+	in, err := confirmInput()
+	if err != nil {
+		return fmt.Errorf("%s needs confirmation but no terminal is available, pass --yes: %w", question, err)
+	}
+	defer in.Close()
+
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return fmt.Errorf("not confirmed, aborting")
+}
+
+// productionBanner is shown on top of the picker preview of production
+// containers.
+func productionBanner(cluster string) string {
+	if !isProduction(cluster) {
+		return ""
+	}
+	return "\x1b[1;37;41m  !!! PRODUCTION !!!  \x1b[0m\n\n"
+}
+
+func productionClusters(resources []myecs.ECSResource) []string {
+	seen := map[string]bool{}
+	var clusters []string
+	for _, resource := range resources {
+		if len(resource.Clusters) == 0 {
+			continue
+		}
+		cluster := resource.Clusters[0].ClusterName
+		if !seen[cluster] && isProduction(cluster) {
+			seen[cluster] = true
+			clusters = append(clusters, cluster)
+		}
+	}
+	sort.Strings(clusters)
+	return clusters
+}
+
+func configPathForHelp() string {
+	path, err := config.Path()
+	if err != nil {
+		return "the config file"
+	}
+	return path
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(
+		&guardSetFlags.context, "context", "", "", "Config context to use (or $MINIECS_CONTEXT)")
+	rootCmd.PersistentFlags().BoolVarP(
+		&guardSetFlags.yes, "yes", "y", false, "Skip confirmations, such as for production clusters")
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func withGuardConfig(t *testing.T, contexts map[string]config.ContextConfig, name string) {
+	t.Helper()
+	saved, savedFlags := appConfig, guardSetFlags
+	t.Cleanup(func() {
+		appConfig, guardSetFlags = saved, savedFlags
+	})
+	t.Setenv("MINIECS_CONTEXT", "")
+	appConfig = config.Default()
+	appConfig.Production.Clusters = []string{"*prod*"}
+	appConfig.Contexts = contexts
+	guardSetFlags.context = name
+	guardSetFlags.yes = false
+}
+
+func TestResolveRegion(t *testing.T) {
+	withGuardConfig(t, map[string]config.ContextConfig{
+		"prod":  {Region: "ap-northeast-1"},
+		"blank": {},
+	}, "")
+
+	region, err := resolveRegion("us-east-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "us-east-1", region)
+
+	_, err = resolveRegion("")
+	assert.Error(t, err)
+
+	guardSetFlags.context = "prod"
+	region, err = resolveRegion("")
+	assert.NoError(t, err)
+	assert.Equal(t, "ap-northeast-1", region)
+
+	guardSetFlags.context = "blank"
+	_, err = resolveRegion("")
+	assert.ErrorContains(t, err, `context "blank" has no region`)
+
+	guardSetFlags.context = ""
+	t.Setenv("MINIECS_CONTEXT", "missing")
+	_, err = resolveRegion("")
+	assert.ErrorContains(t, err, `unknown context "missing"`)
+}
+
+func TestCheckReadonly(t *testing.T) {
+	withGuardConfig(t, map[string]config.ContextConfig{
+		"audit": {Readonly: true},
+	}, "audit")
+
+	stop := &cobra.Command{Use: "stop", Annotations: map[string]string{annotationMutating: "true"}}
+	assert.ErrorContains(t, checkReadonly(stop), `not allowed in readonly context "audit"`)
+	assert.NoError(t, checkReadonly(&cobra.Command{Use: "login"}))
+	assert.Error(t, checkReadonly(execCmd))
+	assert.Error(t, checkReadonly(portForwardCmd))
+	assert.NoError(t, checkReadonly(listCmd))
+
+	guardSetFlags.context = ""
+	assert.NoError(t, checkReadonly(stop))
+}
+
+func TestProductionClusters(t *testing.T) {
+	withGuardConfig(t, map[string]config.ContextConfig{
+		"live": {Production: true},
+	}, "")
+
+	resources := itemsToResources(testSelectableItems())
+	assert.Equal(t, []string{"prod"}, productionClusters(resources))
+	assert.Contains(t, productionBanner("prod"), "PRODUCTION")
+	assert.Empty(t, productionBanner("stg"))
+
+	guardSetFlags.context = "live"
+	assert.Equal(t, []string{"prod", "stg"}, productionClusters(resources))
+}
+
+func TestConfirmProduction(t *testing.T) {
+	withGuardConfig(t, nil, "")
+	savedInput := confirmInput
+	t.Cleanup(func() { confirmInput = savedInput })
+
+	answer := func(s string) {
+		confirmInput = func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(s)), nil
+		}
+	}
+	resources := itemsToResources(testSelectableItems())
+
+	answer("prod\n")
+	assert.NoError(t, confirmProduction(resources))
+
+	answer("stg\n")
+	assert.ErrorContains(t, confirmProduction(resources), "did not match")
+
+	answer("")
+	assert.Error(t, confirmProduction(resources))
+
+	guardSetFlags.yes = true
+	assert.NoError(t, confirmProduction(resources))
+
+	guardSetFlags.yes = false
+	answer("")
+	assert.NoError(t, confirmProduction([]myecs.ECSResource{}))
+	assert.NoError(t, confirmProduction(itemsToResources(testSelectableItems()[3:4])))
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/jedipunkz/miniecs/internal/pkg/audit"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var historySetFlags struct {
+	limit   int
+	cluster string
+	json    bool
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "show the audit log of exec sessions",
+	Long: `Show the sessions recorded in the audit log, oldest first.
+
+Every login and exec session is appended to the audit log with the caller
+identity, target, command, SSM session ID, duration and exit status.`,
+	Args: cobra.NoArgs,
+	RunE: runHistoryCmd,
+}
+
+func runHistoryCmd(cmd *cobra.Command, args []string) error {
+	path, err := appConfig.AuditPath()
+	if err != nil {
+		return err
+	}
+	entries, err := audit.NewLog(path).Read()
+	if err != nil {
+		return err
+	}
+
+	entries = filterHistory(entries, historySetFlags.cluster, historySetFlags.limit)
+	if historySetFlags.json {
+		return writeHistoryJSON(os.Stdout, entries)
+	}
+	return renderHistory(os.Stdout, entries)
+}
+
+// filterHistory keeps the last limit entries of clusters matching the
+// cluster glob. A limit of 0 or less keeps all of them.
+func filterHistory(entries []audit.Entry, cluster string, limit int) []audit.Entry {
+	var filtered []audit.Entry
+	for _, entry := range entries {
+		if cluster != "" {
+			if ok, _ := path.Match(cluster, entry.Cluster); !ok {
+				continue
+			}
+		}
+		filtered = append(filtered, entry)
+	}
+	if limit > 0 && len(filtered) > limit {
+		filtered = filtered[len(filtered)-limit:]
+	}
+	return filtered
+}
+
+func writeHistoryJSON(w io.Writer, entries []audit.Entry) error {
+	encoder := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderHistory(w io.Writer, entries []audit.Entry) error {
+	table := tablewriter.NewTable(w,
+		tablewriter.WithHeader([]string{
+			"Time",
+			"Caller",
+			"Cluster",
+			"Task",
+			"Container",
+			"Command",
+			"Exit Code",
+			"Duration",
+			"Session ID"}))
+	for _, entry := range entries {
+		code := ""
+		switch {
+		case entry.ExitCode != nil:
+			code = strconv.Itoa(*entry.ExitCode)
+		case entry.Error != "":
+			code = "error"
+		}
+		duration := ""
+		if !entry.Detached {
+			duration = (time.Duration(entry.Duration * float64(time.Second))).Round(time.Second).String()
+		}
+		if err := table.Append([]string{
+			entry.Time.Local().Format(time.DateTime),
+			entry.Caller,
+			entry.Cluster,
+			taskID(entry.Task),
+			entry.Container,
+			entry.Command,
+			code,
+			duration,
+			entry.SessionID,
+		}); err != nil {
+			return err
+		}
+	}
+	return table.Render()
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.Flags().IntVarP(
+		&historySetFlags.limit, "limit", "n", 20, "Number of sessions to show, 0 for all")
+	historyCmd.Flags().StringVarP(
+		&historySetFlags.cluster, "cluster", "", "", "Only show sessions in clusters matching this glob")
+	historyCmd.Flags().BoolVarP(
+		&historySetFlags.json, "json", "", false, "Print the entries as JSON lines")
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jedipunkz/miniecs/internal/pkg/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterHistory(t *testing.T) {
+	entries := []audit.Entry{
+		{Cluster: "prod", Command: "1"},
+		{Cluster: "stg", Command: "2"},
+		{Cluster: "prod-eu", Command: "3"},
+		{Cluster: "prod", Command: "4"},
+	}
+
+	assert.Len(t, filterHistory(entries, "", 0), 4)
+	assert.Equal(t, []audit.Entry{entries[2], entries[3]}, filterHistory(entries, "", 2))
+	assert.Equal(t, []audit.Entry{entries[0], entries[2], entries[3]}, filterHistory(entries, "prod*", 20))
+	assert.Empty(t, filterHistory(entries, "dev", 20))
+}
+
+func TestRenderHistory(t *testing.T) {
+	code := 0
+	entries := []audit.Entry{
+		{
+			Cluster:   "prod",
+			Task:      "arn:aws:ecs:ap-northeast-1:123456789012:task/prod/abc123",
+			Container: "app",
+			Command:   "uptime",
+			SessionID: "ecs-execute-command-1",
+			Duration:  61,
+			ExitCode:  &code,
+		},
+		{Cluster: "stg", Error: "access denied", Detached: true},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, renderHistory(&buf, entries))
+	assert.Contains(t, buf.String(), "abc123")
+	assert.Contains(t, buf.String(), "1m1s")
+	assert.Contains(t, buf.String(), "ecs-execute-command-1")
+	assert.Contains(t, buf.String(), "error")
+
+	buf.Reset()
+	assert.NoError(t, writeHistoryJSON(&buf, entries))
+	assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte("\n")))
+}
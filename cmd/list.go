@@ -2,12 +2,12 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"os"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
 	"github.com/olekukonko/tablewriter"
-	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -19,30 +19,35 @@ var listSetFlags struct {
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "list clusters, services",
-	Run:   runlistCmd,
+	RunE:  runlistCmd,
 }
 
-func runlistCmd(cmd *cobra.Command, args []string) {
+func runlistCmd(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(listSetFlags.region))
+	region, err := resolveRegion(listSetFlags.region)
 	if err != nil {
-		log.Fatalf("unable to load SDK config, %v", err)
+		return err
 	}
 
-	e := myecs.NewECS(cfg, listSetFlags.region)
+	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(region))
+	if err != nil {
+		return fmt.Errorf("unable to load SDK config: %w", err)
+	}
+
+	e := myecs.NewECS(cfg, region)
 	if e == nil {
-		log.Fatal("failed to initialize ECS client")
+		return fmt.Errorf("failed to initialize ECS client")
 	}
 
 	err = e.ListClusters(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	ecsTable, err := listECSTable(ctx, e)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	table := tablewriter.NewTable(os.Stdout,
@@ -53,12 +58,10 @@ func runlistCmd(cmd *cobra.Command, args []string) {
 			"Container"}))
 	for _, row := range ecsTable {
 		if err := table.Append(row); err != nil {
-			log.Fatal(err)
+			return err
 		}
 	}
-	if err := table.Render(); err != nil {
-		log.Fatal(err)
-	}
+	return table.Render()
 }
 
 func listECSTable(ctx context.Context, e *myecs.ECSResource) ([][]string, error) {
@@ -109,10 +112,7 @@ func listECSTable(ctx context.Context, e *myecs.ECSResource) ([][]string, error)
 func init() {
 	rootCmd.AddCommand(listCmd)
 	listCmd.Flags().StringVarP(
-		&listSetFlags.region, "region", "", "", "Region Name")
-	if err := listCmd.MarkFlagRequired("region"); err != nil {
-		log.Fatal(err)
-	}
+		&listSetFlags.region, "region", "", "", "Region Name (defaults to the region of --context)")
 	listCmd.Flags().StringVarP(
 		&listSetFlags.cluster, "cluster", "", "", "ECS Cluster Name")
 }
@@ -2,75 +2,174 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
-	"github.com/ktr0731/go-fuzzyfinder"
+	"github.com/jedipunkz/miniecs/internal/pkg/picker"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 type loginFlags struct {
-	region  string
-	cluster string
-	shell   string
+	region      string
+	target      targetFlags
+	shell       string
+	command     string
+	session     sessionFlags
+	tmux        bool
+	tmuxLayout  string
+	synchronize bool
+	record      string
+	last        bool
+	menu        bool
 }
 
 var loginSetFlags loginFlags
 
 var loginCmd = &cobra.Command{
-	Use:   "login",
+	Use:   "login [cluster/service/container | @bookmark | -]",
 	Short: "login cluster, service",
-	Run:   runLoginCmd,
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runLoginCmd,
 }
 
-func runLoginCmd(cmd *cobra.Command, args []string) {
+func runLoginCmd(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
+	stdinForwarded = !term.IsTerminal(int(os.Stdin.Fd()))
+
+	target := loginSetFlags.target
+	last := loginSetFlags.last
+	if len(args) > 0 && args[0] == "-" {
+		last = true
+	} else if name, ok := bookmarkName(firstArg(args)); ok {
+		bookmark, err := lookupBookmark(name)
+		if err != nil {
+			return err
+		}
+		target.applyBookmark(bookmark)
+		applySessionBookmark(&loginSetFlags.region, &loginSetFlags.session, bookmark)
+		if loginSetFlags.shell == "" {
+			loginSetFlags.shell = bookmark.Shell
+		}
+		if loginSetFlags.command == "" {
+			loginSetFlags.command = bookmark.Command
+		}
+	} else if len(args) > 0 {
+		if err := target.applyPath(args[0]); err != nil {
+			return err
+		}
+	}
 
-	ecsClient, err := initializeECSClient(ctx)
-	if err != nil {
-		log.Fatal(err)
+	if err := checkRecord(loginSetFlags.record, loginSetFlags.tmux); err != nil {
+		return err
+	}
+	// Declared commands, like migrations, may change resources, the shell
+	// itself is left to the policy.
+	if loginSetFlags.command != "" {
+		if err := refuseInReadonly("login --command"); err != nil {
+			return err
+		}
 	}
 
-	ecsResources, err := fetchAllECSResources(ctx, ecsClient)
+	ecsClient, selectedResources, action, err := selectLoginTargets(ctx, target, last)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+	loginSetFlags.region = ecsClient.Region
+	registerSecrets(selectedResources)
 
-	selectedResources, err := showResourcePicker(ecsResources)
-	if err != nil {
-		log.Fatal(err)
+	if action == "" && loginSetFlags.menu {
+		p, err := newPicker(target.picker)
+		if err != nil {
+			return err
+		}
+		if action, err = pickAction(p, selectedResources); err != nil {
+			return err
+		}
+	}
+	if action != "" && action != actionShell {
+		return runTargetAction(ctx, ecsClient, action, selectedResources)
 	}
 
-	if err := executeLogin(ecsClient, selectedResources); err != nil {
-		log.Fatal(err)
+	if loginSetFlags.tmux {
+		return executeTmuxLogin(ecsClient, selectedResources, loginSetFlags.tmuxLayout, loginSetFlags.synchronize)
 	}
+	return executeLogin(ecsClient, selectedResources)
 }
 
-func initializeECSClient(ctx context.Context) (*myecs.ECSResource, error) {
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(loginSetFlags.region))
+// selectLoginTargets connects to the region of the login and returns the
+// containers to log in to, the one of the last login when last is set.
+func selectLoginTargets(ctx context.Context, target targetFlags, last bool) (*myecs.ECSResource, []myecs.ECSResource, string, error) {
+	if last {
+		// The region is optional here, the last login knows its own.
+		region := loginSetFlags.region
+		if region == "" {
+			_, current, err := currentContext()
+			if err != nil {
+				return nil, nil, "", err
+			}
+			region = current.Region
+		}
+		ecsClient, selectedResources, err := resolveLastLogin(ctx, region, target)
+		return ecsClient, selectedResources, "", err
+	}
+
+	region, err := resolveRegion(loginSetFlags.region)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	ecsClient, err := initializeECSClient(ctx, region)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	target.actions = true
+	selectedResources, action, err := selectTargetsWithAction(ctx, ecsClient, target)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return ecsClient, selectedResources, action, nil
+}
+
+// sessionConfig is the AWS config of the client made by
+// initializeECSClient.
+var sessionConfig aws.Config
+
+func initializeECSClient(ctx context.Context, region string) (*myecs.ECSResource, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
 	if err != nil {
 		return nil, fmt.Errorf("unable to load SDK config: %w", err)
 	}
 
-	ecsClient := myecs.NewECS(cfg, loginSetFlags.region)
+	ecsClient := myecs.NewECS(cfg, region)
 	if ecsClient == nil {
 		return nil, fmt.Errorf("failed to initialize ECS client")
 	}
+	sessionConfig = cfg
+	sessionIdentity = newAWSIdentity(cfg)
+	enableAudit(ecsClient, sessionIdentity)
 
 	return ecsClient, nil
 }
 
-func fetchAllECSResources(ctx context.Context, ecsClient *myecs.ECSResource) ([]myecs.ECSResource, error) {
+// fetchAllECSResources loads the resources of every cluster, or only of the
+// named cluster when cluster is not empty.
+func fetchAllECSResources(ctx context.Context, ecsClient *myecs.ECSResource, cluster string) ([]myecs.ECSResource, error) {
 	var ecsResources []myecs.ECSResource
 
 	if err := ecsClient.ListClusters(ctx); err != nil {
 		return nil, fmt.Errorf("failed to list clusters: %w", err)
 	}
 
-	for _, cluster := range ecsClient.Clusters {
+	for _, cluster := range clustersNamed(ecsClient.Clusters, cluster) {
 		resources, err := ecsClient.GetClusterResources(ctx, cluster)
 		if err != nil {
 			return nil, err
@@ -81,8 +180,22 @@ func fetchAllECSResources(ctx context.Context, ecsClient *myecs.ECSResource) ([]
 	return ecsResources, nil
 }
 
+func clustersNamed(clusters []myecs.ECSCluster, name string) []myecs.ECSCluster {
+	if name == "" {
+		return clusters
+	}
+	var named []myecs.ECSCluster
+	for _, cluster := range clusters {
+		if cluster.ClusterName == name {
+			named = append(named, cluster)
+		}
+	}
+	return named
+}
+
 type selectableItem struct {
 	resourceIndex int
+	region        string
 	cluster       myecs.ECSCluster
 	service       myecs.ECSService
 	task          myecs.ECSTask
@@ -135,78 +248,176 @@ func extractItemsFromContainers(resourceIndex int, cluster myecs.ECSCluster, ser
 	return items
 }
 
-func showResourcePicker(ecsResources []myecs.ECSResource) ([]myecs.ECSResource, error) {
+func showResourcePicker(p picker.Picker, ecsResources []myecs.ECSResource, drill bool) ([]myecs.ECSResource, error) {
 	items := buildSelectableItems(ecsResources)
 	if len(items) == 0 {
-		return nil, fmt.Errorf("no ECS resources found")
-	}
-
-	selectedIndices, err := fuzzyfinder.FindMulti(
-		items,
-		func(i int) string {
-			return fmt.Sprintf("%s %s %s",
-				items[i].cluster.ClusterName,
-				items[i].service.ServiceName,
-				items[i].container.ContainerName,
-			)
-		},
-		fuzzyfinder.WithPreviewWindow(func(i, w, h int) string {
-			if i == -1 {
-				return ""
+		return nil, myecs.ErrNoResources
+	}
+	resources, _, err := pickItems(p, items, appConfig.Sidecars.Show, drill, false)
+	return resources, err
+}
+
+// pickItems lets the user choose from items with the flat picker, or with
+// the drill-down picker when drill is set. With actions set, pickers that
+// support it accept the selection with the keys of targetActions, and the
+// name of the chosen action is returned.
+func pickItems(p picker.Picker, items []selectableItem, showSidecars, drill, actions bool) ([]myecs.ECSResource, string, error) {
+	if drill {
+		resources, err := drillSelectableItems(p, items, showSidecars)
+		return resources, "", err
+	}
+	return pickSelectableItems(p, items, showSidecars, actions)
+}
+
+// sidecarToggleKey shows and hides the sidecar containers in pickers that
+// support keys.
+const sidecarToggleKey = "ctrl-s"
+
+// pickSelectableItems lets the user choose from items. Unless showSidecars
+// is set, sidecar containers are left out and sidecarToggleKey shows them.
+// The built-in picker and the prompt cannot bind keys, so they get an extra
+// entry at the end of the list instead.
+func pickSelectableItems(p picker.Picker, items []selectableItem, showSidecars, actions bool) ([]myecs.ECSResource, string, error) {
+	primary := withoutSidecars(items)
+	sidecarCount := len(items) - len(primary)
+	if len(primary) == 0 {
+		showSidecars = true
+	}
+	toggleable := sidecarCount > 0 && len(primary) > 0
+	keyPicker, withKeys := p.(picker.KeyPicker)
+
+	for {
+		visible := items
+		if !showSidecars {
+			visible = primary
+		}
+
+		labels, err := pickerLabels(appConfig.Picker.Format, visible, time.Now())
+		if err != nil {
+			return nil, "", err
+		}
+		toggleIndex := -1
+		if toggleable && !withKeys {
+			toggleIndex = len(visible)
+			labels = append(labels, "» "+sidecarToggleLabel(showSidecars, sidecarCount))
+		}
+
+		opts := picker.Options{
+			Multi: true,
+			Preview: func(i int) string {
+				if i == toggleIndex {
+					return ""
+				}
+				return itemPreview(visible[i], time.Now())
+			},
+		}
+		var keys, hints []string
+		if withKeys && actions {
+			keys = append(keys, actionKeys()...)
+			hints = append(hints, actionKeysHeader())
+		}
+		if withKeys && toggleable {
+			keys = append(keys, sidecarToggleKey)
+			hints = append(hints, sidecarToggleKey+": "+sidecarToggleLabel(showSidecars, sidecarCount))
+		}
+
+		var selectedIndices []int
+		var key string
+		if len(keys) > 0 {
+			opts.Header = strings.Join(hints, "  ")
+			selectedIndices, key, err = keyPicker.PickKey(labels, opts, keys)
+		} else {
+			selectedIndices, err = p.Pick(labels, opts)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if key == sidecarToggleKey {
+			showSidecars = !showSidecars
+			continue
+		}
+
+		var selectedResources []myecs.ECSResource
+		toggled := false
+		for _, idx := range selectedIndices {
+			if idx == toggleIndex {
+				toggled = true
+				continue
+			}
+			selectedResources = append(selectedResources, visible[idx].toResource())
+		}
+		if toggled {
+			if len(selectedResources) == 0 {
+				showSidecars = !showSidecars
+				continue
 			}
-			return fmt.Sprintf(
-				"Cluster: %s\nService: %s\nContainer: %s\n",
-				items[i].cluster.ClusterName,
-				items[i].service.ServiceName,
-				items[i].container.ContainerName,
-			)
-		}),
-	)
+			// The built-in picker cannot keep the entry out of Tab
+			// selection, it is not a container to log in to.
+			log.Warnf("ignoring %q, it cannot be selected together with containers",
+				sidecarToggleLabel(showSidecars, sidecarCount))
+		}
 
-	if err != nil {
-		return nil, err
-	}
-
-	// Create resources with selected items
-	var selectedResources []myecs.ECSResource
-	for _, idx := range selectedIndices {
-		selectedItem := items[idx]
-		// Create a new resource with the selected item data
-		resource := myecs.ECSResource{
-			Clusters: []myecs.ECSCluster{{
-				ClusterName: selectedItem.cluster.ClusterName,
-				ClusterArn:  selectedItem.cluster.ClusterArn,
-				Services: []myecs.ECSService{{
-					ServiceName: selectedItem.service.ServiceName,
-					ServiceArn:  selectedItem.service.ServiceArn,
-					ClusterName: selectedItem.cluster.ClusterName,
-					Tasks: []myecs.ECSTask{{
-						TaskArn:        selectedItem.task.TaskArn,
-						TaskDefinition: selectedItem.task.TaskDefinition,
-						ServiceName:    selectedItem.service.ServiceName,
-						ClusterName:    selectedItem.cluster.ClusterName,
-						Containers: []myecs.ECSContainer{{
-							ContainerName: selectedItem.container.ContainerName,
-							ContainerArn:  selectedItem.container.ContainerArn,
-							TaskArn:       selectedItem.task.TaskArn,
-							Image:         selectedItem.container.Image,
-							Status:        selectedItem.container.Status,
-						}},
-					}},
-				}},
-			}},
+		if !actions {
+			return selectedResources, "", nil
 		}
-		selectedResources = append(selectedResources, resource)
+		return selectedResources, actionForKey(key), nil
+	}
+}
+
+func sidecarToggleLabel(showSidecars bool, sidecarCount int) string {
+	if showSidecars {
+		return fmt.Sprintf("hide %d sidecar containers", sidecarCount)
 	}
+	return fmt.Sprintf("show %d sidecar containers", sidecarCount)
+}
 
-	return selectedResources, nil
+// toResource creates a resource holding only the selected item data.
+func (item selectableItem) toResource() myecs.ECSResource {
+	container := item.container
+	container.TaskArn = item.task.TaskArn
+
+	task := item.task
+	task.ServiceName = item.service.ServiceName
+	task.ClusterName = item.cluster.ClusterName
+	task.Containers = []myecs.ECSContainer{container}
+
+	return myecs.ECSResource{
+		Region: item.region,
+		Clusters: []myecs.ECSCluster{{
+			ClusterName: item.cluster.ClusterName,
+			ClusterArn:  item.cluster.ClusterArn,
+			Services: []myecs.ECSService{{
+				ServiceName: item.service.ServiceName,
+				ServiceArn:  item.service.ServiceArn,
+				ClusterName: item.cluster.ClusterName,
+				Tasks:       []myecs.ECSTask{task},
+			}},
+		}},
+	}
 }
 
 func executeLogin(ecsClient *myecs.ECSResource, selectedResources []myecs.ECSResource) error {
 	if len(selectedResources) == 0 {
 		return fmt.Errorf("no resource selected")
 	}
+	if len(selectedResources) > 1 {
+		log.Warnf("%d containers selected, logging in to the first one, use --tmux to open all of them", len(selectedResources))
+	}
 	selectedResource := selectedResources[0]
+	if err := checkLoginCommand(selectedResource); err != nil {
+		return err
+	}
+	// Piped input runs without a terminal, the status of the last command
+	// read from stdin is then handed back as our own exit code.
+	interactive := loginSetFlags.record != "" || term.IsTerminal(int(os.Stdin.Fd()))
+	if err := checkLoginPolicy(selectedResources[:1], interactive); err != nil {
+		return err
+	}
+	if err := confirmProduction(selectedResources[:1]); err != nil {
+		return err
+	}
+	detectShell(ecsClient, selectedResource)
+	rememberOnSession(ecsClient, selectedResource)
 	commandInput := createExecuteCommandInput(selectedResource)
 
 	log.WithFields(log.Fields{
@@ -216,11 +427,32 @@ func executeLogin(ecsClient *myecs.ECSResource, selectedResources []myecs.ECSRes
 		"command":   *commandInput.Command,
 	}).Info("ECS Execute Login with These Parameters")
 
-	return ecsClient.ExecuteCommand(commandInput)
+	var err error
+	if loginSetFlags.record != "" {
+		err = recordLogin(ecsClient, commandInput, selectedResource, loginSetFlags.record)
+	} else if interactive {
+		err = ecsClient.ExecuteCommand(commandInput)
+	} else {
+		err = ecsClient.ExecuteCommandWithExitCode(commandInput, myecs.ExecOptions{
+			Stdin:   os.Stdin,
+			Windows: isWindows(selectedResource),
+		})
+	}
+	return withService(err, selectedResource)
+}
+
+// withService records the service of resource on err so that hints can name
+// it; the ECS execute command API only knows about tasks.
+func withService(err error, resource myecs.ECSResource) error {
+	var ecsErr *myecs.Error
+	if errors.As(err, &ecsErr) && len(resource.Clusters) > 0 && len(resource.Clusters[0].Services) > 0 {
+		ecsErr.Service = resource.Clusters[0].Services[0].ServiceName
+	}
+	return err
 }
 
 func createExecuteCommandInput(resource myecs.ECSResource) ecs.ExecuteCommandInput {
-	shell := getShell()
+	command := commandLine(loginSpec(resource))
 	containerName, taskArn := extractTaskAndContainer(resource)
 	clusterName := resource.Clusters[0].ClusterName
 
@@ -228,14 +460,63 @@ func createExecuteCommandInput(resource myecs.ECSResource) ecs.ExecuteCommandInp
 		Cluster:   &clusterName,
 		Container: &containerName,
 		Task:      &taskArn,
-		Command:   &shell,
+		Command:   &command,
+	}
+}
+
+// loginSpec combines the login flags with the defaults the container
+// declares through its docker labels.
+func loginSpec(resource myecs.ECSResource) myecs.LoginSpec {
+	spec := sessionSpec(resource, loginSetFlags.session)
+	spec.Shell = getShell(resource)
+	if container := selectedContainer(resource); container != nil && loginSetFlags.command != "" {
+		spec.Command = container.Commands[loginSetFlags.command]
 	}
+	// Template errors are reported by checkLoginCommand before login.
+	spec.Prompt, spec.PromptColor, _ = renderPrompt(resource, loginSetFlags.region)
+	return spec
 }
 
-func getShell() string {
+// checkLoginCommand verifies that the session can be started and that the
+// command named by --command is declared by the selected container.
+func checkLoginCommand(resource myecs.ECSResource) error {
+	if err := loginSpec(resource).Validate(); err != nil {
+		return err
+	}
+	if _, _, err := renderPrompt(resource, loginSetFlags.region); err != nil {
+		return err
+	}
+	if loginSetFlags.command == "" {
+		return nil
+	}
+	container := selectedContainer(resource)
+	if container == nil {
+		return fmt.Errorf("no container selected")
+	}
+	if _, ok := container.Commands[loginSetFlags.command]; ok {
+		return nil
+	}
+
+	var names []string
+	for name := range container.Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("container %s does not declare command %q (available: %s), add a %s%s docker label",
+		container.ContainerName, loginSetFlags.command, strings.Join(names, ", "),
+		myecs.LabelCommandsPrefix, loginSetFlags.command)
+}
+
+func getShell(resource myecs.ECSResource) string {
 	if loginSetFlags.shell != "" {
 		return loginSetFlags.shell
 	}
+	if container := selectedContainer(resource); container != nil && container.Shell != "" {
+		return container.Shell
+	}
+	if task := selectedTask(resource); task != nil && task.IsWindows() {
+		return "powershell.exe"
+	}
 	return "sh"
 }
 
@@ -267,12 +548,23 @@ func extractTaskAndContainer(resource myecs.ECSResource) (containerName, taskArn
 func init() {
 	rootCmd.AddCommand(loginCmd)
 	loginCmd.Flags().StringVarP(
-		&loginSetFlags.region, "region", "", "", "Region Name")
-	if err := loginCmd.MarkFlagRequired("region"); err != nil {
-		log.Fatal(err)
-	}
+		&loginSetFlags.region, "region", "", "", "Region Name (defaults to the region of --context)")
+	addTargetFlags(loginCmd, &loginSetFlags.target)
+	loginCmd.Flags().StringVarP(
+		&loginSetFlags.shell, "shell", "", "", "Login Shell (detected when omitted)")
+	addSessionFlags(loginCmd, &loginSetFlags.session)
+	loginCmd.Flags().StringVarP(
+		&loginSetFlags.command, "command", "c", "", "Run a command declared with a miniecs.commands.<name> docker label")
+	loginCmd.Flags().BoolVarP(
+		&loginSetFlags.tmux, "tmux", "", false, "Open every selected container in tmux")
 	loginCmd.Flags().StringVarP(
-		&loginSetFlags.cluster, "cluster", "", "", "ECS Cluster Name")
+		&loginSetFlags.tmuxLayout, "tmux-layout", "", tmuxPanes, "tmux layout: panes or windows")
+	loginCmd.Flags().BoolVarP(
+		&loginSetFlags.synchronize, "synchronize-panes", "", false, "Send input to all tmux panes at once")
 	loginCmd.Flags().StringVarP(
-		&loginSetFlags.shell, "shell", "", "", "Login Shell")
+		&loginSetFlags.record, "record", "", "", "Record the session to an asciicast v2 file")
+	loginCmd.Flags().BoolVarP(
+		&loginSetFlags.last, "last", "", false, "Log in to the container of the last login again (same as login -)")
+	loginCmd.Flags().BoolVarP(
+		&loginSetFlags.menu, "menu", "m", false, "Choose what to do with the selected container from a menu")
 }
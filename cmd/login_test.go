@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -39,3 +40,62 @@ func TestCreateExecuteCommandInput(t *testing.T) {
 	assert.Equal(t, "arn:aws:ecs:us-east-1:123456789012:task/test-task", *result.Task)
 	assert.Equal(t, "sh", *result.Command)
 }
+
+func TestGetShell(t *testing.T) {
+	defer func(shell string) { loginSetFlags.shell = shell }(loginSetFlags.shell)
+
+	resource := testSelectableItems()[0].toResource()
+	loginSetFlags.shell = ""
+	assert.Equal(t, "sh", getShell(resource))
+
+	selectedContainer(resource).Shell = "bash"
+	assert.Equal(t, "bash", getShell(resource))
+
+	loginSetFlags.shell = "zsh"
+	assert.Equal(t, "zsh", getShell(resource))
+
+	loginSetFlags.shell = ""
+	windows := testSelectableItems()[0]
+	windows.task.OSFamily = "WINDOWS_SERVER_2019_CORE"
+	assert.Equal(t, "powershell.exe", getShell(windows.toResource()))
+}
+
+func TestCheckLoginCommand(t *testing.T) {
+	defer func(command string) { loginSetFlags.command = command }(loginSetFlags.command)
+
+	item := testSelectableItems()[0]
+	item.container.Commands = map[string]string{"console": "bin/rails c"}
+	resource := item.toResource()
+
+	loginSetFlags.command = ""
+	assert.NoError(t, checkLoginCommand(resource))
+
+	loginSetFlags.command = "console"
+	assert.NoError(t, checkLoginCommand(resource))
+	assert.Equal(t, "bin/rails c", loginSpec(resource).Command)
+
+	loginSetFlags.command = "dbconsole"
+	assert.ErrorContains(t, checkLoginCommand(resource), "miniecs.commands.dbconsole")
+}
+
+func TestRenderPrompt(t *testing.T) {
+	defer func(cfg *config.Config) { appConfig = cfg }(appConfig)
+	appConfig = config.Default()
+	appConfig.Production.Clusters = []string{"*prod*"}
+	resource := testSelectableItems()[0].toResource()
+
+	prompt, color, err := renderPrompt(resource, "ap-northeast-1")
+	assert.NoError(t, err)
+	assert.Empty(t, prompt)
+	assert.Empty(t, color)
+
+	appConfig.Prompt.Template = "[{{.Region}} {{.Cluster}}/{{.Service}}/{{.Container}}] $ "
+	prompt, color, err = renderPrompt(resource, "ap-northeast-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "[ap-northeast-1 prod/api/app] $ ", prompt)
+	assert.Equal(t, "1;31", color)
+
+	appConfig.Prompt.Template = "{{.Nope}}"
+	_, _, err = renderPrompt(resource, "ap-northeast-1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	log "github.com/sirupsen/logrus"
+)
+
+// Task selection strategies of --pick.
+const (
+	pickNewest      = "newest"
+	pickOldest      = "oldest"
+	pickRandom      = "random"
+	pickLeastLoaded = "least-loaded"
+	pickZonePrefix  = "az="
+)
+
+// randomIndex returns a random index below n.
+var randomIndex = rand.IntN
+
+// taskSessions counts the Session Manager sessions currently connected to
+// each task, by task ID. It returns nil when they cannot be listed.
+var taskSessions = func(ctx context.Context, ecsClient *myecs.ECSResource) map[string]int {
+	sessions, err := ecsClient.ActiveSessions(ctx)
+	if err != nil {
+		log.Warnf("--pick %s cannot count active sessions, choosing the first task: %v", pickLeastLoaded, err)
+		return nil
+	}
+	return sessions
+}
+
+func validatePick(strategy string) error {
+	switch strategy {
+	case "", pickNewest, pickOldest, pickRandom, pickLeastLoaded:
+		return nil
+	}
+	if zone, ok := strings.CutPrefix(strategy, pickZonePrefix); ok && zone != "" {
+		return nil
+	}
+	return fmt.Errorf("invalid --pick %q, expected %s, %s, %s, %s or %s<zone>",
+		strategy, pickNewest, pickOldest, pickRandom, pickLeastLoaded, pickZonePrefix)
+}
+
+// pickTask chooses one of the tasks of items with strategy and returns the
+// items of that task. Running tasks are preferred over others, and healthy
+// ones over tasks failing their health checks.
+func pickTask(ctx context.Context, ecsClient *myecs.ECSResource, items []selectableItem, strategy string) ([]selectableItem, error) {
+	tasks := distinctTasks(items)
+	if zone, ok := strings.CutPrefix(strategy, pickZonePrefix); ok {
+		tasks = tasksInZone(tasks, zone)
+		if len(tasks) == 0 {
+			if zones := taskZones(distinctTasks(items)); len(zones) > 0 {
+				return nil, fmt.Errorf("no task in availability zone %s, tasks run in %s", zone, strings.Join(zones, ", "))
+			}
+			return nil, fmt.Errorf("no task in availability zone %s", zone)
+		}
+	}
+	tasks = preferredTasks(tasks)
+
+	chosen := tasks[0]
+	switch strategy {
+	case pickNewest:
+		for _, task := range tasks[1:] {
+			if task.task.StartedAt.After(chosen.task.StartedAt) {
+				chosen = task
+			}
+		}
+	case pickOldest:
+		for _, task := range tasks[1:] {
+			if !task.task.StartedAt.IsZero() &&
+				(chosen.task.StartedAt.IsZero() || task.task.StartedAt.Before(chosen.task.StartedAt)) {
+				chosen = task
+			}
+		}
+	case pickRandom:
+		chosen = tasks[randomIndex(len(tasks))]
+	case pickLeastLoaded:
+		sessions := taskSessions(ctx, ecsClient)
+		for _, task := range tasks[1:] {
+			if sessions[taskID(task.task.TaskArn)] < sessions[taskID(chosen.task.TaskArn)] {
+				chosen = task
+			}
+		}
+	}
+
+	var picked []selectableItem
+	for _, item := range items {
+		if item.task.TaskArn == chosen.task.TaskArn {
+			picked = append(picked, item)
+		}
+	}
+	return picked, nil
+}
+
+// distinctTasks returns the first item of every task in items.
+func distinctTasks(items []selectableItem) []selectableItem {
+	seen := map[string]bool{}
+	var tasks []selectableItem
+	for _, item := range items {
+		if !seen[item.task.TaskArn] {
+			seen[item.task.TaskArn] = true
+			tasks = append(tasks, item)
+		}
+	}
+	return tasks
+}
+
+func tasksInZone(tasks []selectableItem, zone string) []selectableItem {
+	var filtered []selectableItem
+	for _, task := range tasks {
+		if task.task.AvailabilityZone == zone {
+			filtered = append(filtered, task)
+		}
+	}
+	return filtered
+}
+
+func taskZones(tasks []selectableItem) []string {
+	seen := map[string]bool{}
+	var zones []string
+	for _, task := range tasks {
+		zone := task.task.AvailabilityZone
+		if zone != "" && !seen[zone] {
+			seen[zone] = true
+			zones = append(zones, zone)
+		}
+	}
+	return zones
+}
+
+// preferredTasks narrows tasks down to the running ones, and those to the
+// ones not reported unhealthy, as long as any are left.
+func preferredTasks(tasks []selectableItem) []selectableItem {
+	for _, keep := range []func(selectableItem) bool{
+		func(item selectableItem) bool { return item.task.LastStatus == "RUNNING" },
+		func(item selectableItem) bool { return item.task.HealthStatus != "UNHEALTHY" },
+	} {
+		var filtered []selectableItem
+		for _, task := range tasks {
+			if keep(task) {
+				filtered = append(filtered, task)
+			}
+		}
+		if len(filtered) > 0 {
+			tasks = filtered
+		}
+	}
+	return tasks
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+func pickTestItems() []selectableItem {
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	newItem := func(task, container, zone, status, health string, age time.Duration) selectableItem {
+		return selectableItem{
+			cluster: myecs.ECSCluster{ClusterName: "prod"},
+			service: myecs.ECSService{ServiceName: "api"},
+			task: myecs.ECSTask{
+				TaskArn:          "arn:aws:ecs:ap-northeast-1:123456789012:task/prod/" + task,
+				LastStatus:       status,
+				HealthStatus:     health,
+				AvailabilityZone: zone,
+				StartedAt:        started.Add(-age),
+			},
+			container: myecs.ECSContainer{ContainerName: container, Essential: true},
+		}
+	}
+	return []selectableItem{
+		newItem("task1", "app", "ap-northeast-1a", "RUNNING", "HEALTHY", 2*time.Hour),
+		newItem("task1", "envoy", "ap-northeast-1a", "RUNNING", "HEALTHY", 2*time.Hour),
+		newItem("task2", "app", "ap-northeast-1c", "RUNNING", "HEALTHY", time.Hour),
+		newItem("task3", "app", "ap-northeast-1c", "RUNNING", "UNHEALTHY", time.Minute),
+		newItem("task4", "app", "ap-northeast-1d", "PROVISIONING", "UNKNOWN", 0),
+		newItem("task5", "app", "ap-northeast-1a", "RUNNING", "UNKNOWN", 3*time.Hour),
+	}
+}
+
+func pickedTasks(items []selectableItem) []string {
+	var tasks []string
+	for _, item := range items {
+		tasks = append(tasks, taskID(item.task.TaskArn)+"/"+item.container.ContainerName)
+	}
+	return tasks
+}
+
+func TestPickTask(t *testing.T) {
+	savedRandom, savedSessions := randomIndex, taskSessions
+	t.Cleanup(func() { randomIndex, taskSessions = savedRandom, savedSessions })
+	randomIndex = func(n int) int { return n - 1 }
+	taskSessions = func(context.Context, *myecs.ECSResource) map[string]int {
+		return map[string]int{"task1": 3, "task2": 1, "task5": 2}
+	}
+
+	tests := []struct {
+		strategy string
+		want     []string
+	}{
+		// task3 is unhealthy and task4 not running yet
+		{pickNewest, []string{"task2/app"}},
+		{pickOldest, []string{"task5/app"}},
+		{pickRandom, []string{"task5/app"}},
+		{pickLeastLoaded, []string{"task2/app"}},
+		{"az=ap-northeast-1a", []string{"task1/app", "task1/envoy"}},
+		// tasks not running yet are taken when nothing else is left
+		{"az=ap-northeast-1d", []string{"task4/app"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			picked, err := pickTask(context.Background(), nil, pickTestItems(), tt.strategy)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, pickedTasks(picked))
+		})
+	}
+
+	// without session counts the first preferred task is taken
+	taskSessions = savedSessions
+	picked, err := pickTask(context.Background(), &myecs.ECSResource{}, pickTestItems(), pickLeastLoaded)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"task1/app", "task1/envoy"}, pickedTasks(picked))
+
+	_, err = pickTask(context.Background(), nil, pickTestItems(), "az=us-east-1a")
+	assert.EqualError(t, err, "no task in availability zone us-east-1a, tasks run in ap-northeast-1a, ap-northeast-1c, ap-northeast-1d")
+}
+
+func TestValidatePick(t *testing.T) {
+	for _, strategy := range []string{"", "newest", "oldest", "random", "least-loaded", "az=ap-northeast-1a"} {
+		assert.NoError(t, validatePick(strategy), strategy)
+	}
+	assert.Error(t, validatePick("az="))
+	assert.ErrorContains(t, validatePick("fastest"), `invalid --pick "fastest"`)
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/jedipunkz/miniecs/internal/pkg/picker"
+)
+
+// stdinForwarded is set when stdin carries input for the remote command,
+// with exec --stdin and with input piped to login, so that no picker reads
+// it.
+var stdinForwarded bool
+
+// newPicker returns the picker for backend, falling back to the one of the
+// config file.
+func newPicker(backend string) (picker.Picker, error) {
+	if backend == "" {
+		backend = appConfig.Picker.Backend
+	}
+	if backend == "" {
+		backend = defaultPickerBackend()
+	}
+	p, err := picker.New(backend, appConfig.Picker.Args)
+	if prompt, ok := p.(*picker.Prompt); ok {
+		prompt.Stdin = !stdinForwarded
+	}
+	return p, err
+}
+
+// defaultPickerBackend falls back to the numbered prompt where the fuzzy
+// finder cannot draw, on dumb terminals and without a terminal at all. Both
+// draw on the terminal when stdin is piped.
+func defaultPickerBackend() string {
+	if os.Getenv("TERM") == "dumb" {
+		return picker.BackendPrompt
+	}
+	tty, err := confirmInput()
+	if err != nil {
+		return picker.BackendPrompt
+	}
+	tty.Close()
+	return picker.BackendBuiltin
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jedipunkz/miniecs/internal/pkg/config"
+	"github.com/jedipunkz/miniecs/internal/pkg/picker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPicker(t *testing.T) {
+	saved := appConfig
+	t.Cleanup(func() { appConfig = saved })
+	appConfig = config.Default()
+	appConfig.Picker = config.PickerConfig{Backend: picker.BackendPrompt, Args: []string{"--height=40%"}}
+
+	p, err := newPicker("")
+	assert.NoError(t, err)
+	assert.IsType(t, &picker.Prompt{}, p)
+	assert.True(t, p.(*picker.Prompt).Stdin)
+
+	stdinForwarded = true
+	t.Cleanup(func() { stdinForwarded = false })
+	p, err = newPicker("")
+	assert.NoError(t, err)
+	assert.False(t, p.(*picker.Prompt).Stdin)
+
+	p, err = newPicker(picker.BackendFzf)
+	assert.NoError(t, err)
+	assert.Equal(t, &picker.Command{Name: "fzf", Args: []string{"--height=40%"}}, p)
+
+	_, err = newPicker("peco")
+	assert.Error(t, err)
+}
+
+func TestDefaultPickerBackend(t *testing.T) {
+	saved := confirmInput
+	t.Cleanup(func() { confirmInput = saved })
+	t.Setenv("TERM", "xterm")
+
+	// piped stdin still draws on the terminal
+	confirmInput = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader("")), nil }
+	assert.Equal(t, picker.BackendBuiltin, defaultPickerBackend())
+
+	confirmInput = func() (io.ReadCloser, error) { return nil, errors.New("no tty") }
+	assert.Equal(t, picker.BackendPrompt, defaultPickerBackend())
+
+	t.Setenv("TERM", "dumb")
+	confirmInput = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader("")), nil }
+	assert.Equal(t, picker.BackendPrompt, defaultPickerBackend())
+}
+
+func TestPickSelectableItems(t *testing.T) {
+	items := testSelectableItems()[:4]
+
+	// the last entry shows the envoy sidecar, which is then chosen
+	p := &fakePicker{t: t, choices: []int{3, 1}}
+	resources, _, err := pickSelectableItems(p, items, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "prod/api/envoy", drilledTarget(resources))
+	assert.Len(t, p.headers, 2)
+
+	// marked together with a container, the entry is ignored
+	multi := &multiPicker{choices: []int{0, 3}}
+	resources, _, err = pickSelectableItems(multi, items, false, false)
+	assert.NoError(t, err)
+	assert.Len(t, resources, 1)
+	assert.Equal(t, "prod/api/app", drilledTarget(resources))
+}
+
+// multiPicker accepts all of choices at once.
+type multiPicker struct {
+	choices []int
+}
+
+func (p *multiPicker) Pick(labels []string, opts picker.Options) ([]int, error) {
+	return p.choices, nil
+}
+
+func TestPickSelectableItemsSidecarKey(t *testing.T) {
+	items := testSelectableItems()[:4]
+
+	// pickers with keys toggle the sidecars with a key, not an entry
+	p := &fakeKeyPicker{keys: []string{sidecarToggleKey, ""}}
+	resources, _, err := pickSelectableItems(p, items, false, false)
+	assert.NoError(t, err)
+	assert.Len(t, resources, 1)
+	assert.Len(t, p.labels, 2)
+	assert.Len(t, p.labels[0], 3)
+	assert.Len(t, p.labels[1], 4)
+	assert.NotContains(t, strings.Join(p.labels[1], "\n"), "sidecar containers")
+	assert.Equal(t, "ctrl-s: hide 1 sidecar containers", p.header)
+}
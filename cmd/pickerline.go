@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jedipunkz/miniecs/internal/pkg/config"
+	"github.com/jedipunkz/miniecs/internal/pkg/picker"
+	"github.com/mattn/go-runewidth"
+)
+
+// defaultPickerFormat is the picker line used when the config has none.
+const defaultPickerFormat = "{{.Cluster}} {{.Service}} {{.Container}}"
+
+// pickerLineData is what the picker line template is rendered with.
+type pickerLineData struct {
+	Region    string
+	Cluster   string
+	Service   string
+	Container string
+	// Task is the task ID and ShortTask its first 8 characters.
+	Task           string
+	ShortTask      string
+	TaskDefinition string
+	Status         string
+	Health         string
+	Zone           string
+	PrivateIP      string
+	LaunchType     string
+	Image          string
+	ImageDigest    string
+	// Age is the time since the task started, like 3h12m.
+	Age        string
+	Production bool
+	Sidecar    bool
+}
+
+var pickerLineFuncs = template.FuncMap{
+	"tag":  imageTag,
+	"repo": config.ImageRepository,
+	// color wraps s in the ANSI SGR colour code, e.g. "1;31".
+	"color": func(code, s string) string {
+		if s == "" {
+			return ""
+		}
+		return "\x1b[" + code + "m" + s + "\x1b[0m"
+	},
+}
+
+// pickerLabels renders the picker line of every item with format. Fields
+// of the format separated by spaces are aligned in columns.
+func pickerLabels(format string, items []selectableItem, now time.Time) ([]string, error) {
+	if format == "" {
+		format = defaultPickerFormat
+	}
+
+	var columns []*template.Template
+	for _, field := range splitFormatFields(format) {
+		tmpl, err := template.New("picker").Funcs(pickerLineFuncs).Parse(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid picker format: %w", err)
+		}
+		columns = append(columns, tmpl)
+	}
+
+	cells := make([][]string, len(items))
+	widths := make([]int, len(columns))
+	for i, item := range items {
+		data := newPickerLineData(item, now)
+		for j, column := range columns {
+			var cell strings.Builder
+			if err := column.Execute(&cell, data); err != nil {
+				return nil, fmt.Errorf("invalid picker format: %w", err)
+			}
+			cells[i] = append(cells[i], cell.String())
+			widths[j] = max(widths[j], displayWidth(cell.String()))
+		}
+	}
+
+	labels := make([]string, len(items))
+	for i, row := range cells {
+		var line strings.Builder
+		for j, cell := range row {
+			if j > 0 {
+				line.WriteByte(' ')
+			}
+			line.WriteString(cell)
+			if j < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[j]-displayWidth(cell)))
+			}
+		}
+		labels[i] = strings.TrimRight(line.String(), " ")
+	}
+	return labels, nil
+}
+
+func newPickerLineData(item selectableItem, now time.Time) pickerLineData {
+	id := taskID(item.task.TaskArn)
+	data := pickerLineData{
+		Region:         item.region,
+		Cluster:        item.cluster.ClusterName,
+		Service:        item.service.ServiceName,
+		Container:      item.container.ContainerName,
+		Task:           id,
+		ShortTask:      id[:min(len(id), 8)],
+		TaskDefinition: taskDefinitionName(item.task.TaskDefinition),
+		Status:         item.task.LastStatus,
+		Health:         item.task.HealthStatus,
+		Zone:           item.task.AvailabilityZone,
+		PrivateIP:      item.task.PrivateIP,
+		LaunchType:     launchType(item.task.LaunchType, item.task.CapacityProvider),
+		Image:          item.container.Image,
+		ImageDigest:    item.container.ImageDigest,
+		Production:     isProduction(item.cluster.ClusterName),
+		Sidecar:        item.isSidecar(),
+	}
+	if !item.task.StartedAt.IsZero() {
+		data.Age = formatAge(now.Sub(item.task.StartedAt))
+	}
+	return data
+}
+
+// splitFormatFields splits format at the spaces outside of template
+// actions.
+func splitFormatFields(format string) []string {
+	var fields []string
+	var field strings.Builder
+	depth := 0
+	for i := 0; i < len(format); i++ {
+		switch {
+		case strings.HasPrefix(format[i:], "{{"):
+			depth++
+			field.WriteString("{{")
+			i++
+		case strings.HasPrefix(format[i:], "}}") && depth > 0:
+			depth--
+			field.WriteString("}}")
+			i++
+		case format[i] == ' ' && depth == 0:
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteByte(format[i])
+		}
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
+
+// imageTag returns the tag or digest of an image, latest when it has
+// neither.
+func imageTag(image string) string {
+	if _, digest, ok := strings.Cut(image, "@"); ok {
+		return digest
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if _, tag, ok := strings.Cut(name, ":"); ok {
+		return tag
+	}
+	return "latest"
+}
+
+// displayWidth is the number of terminal cells s takes up.
+func displayWidth(s string) int {
+	return runewidth.StringWidth(picker.StripANSI(s))
+}
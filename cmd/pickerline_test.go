@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPickerLabels(t *testing.T) {
+	withGuardConfig(t, nil, "")
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	items := []selectableItem{
+		{
+			region:    "ap-northeast-1",
+			cluster:   myecs.ECSCluster{ClusterName: "stg"},
+			service:   myecs.ECSService{ServiceName: "api"},
+			task:      myecs.ECSTask{TaskArn: "arn:aws:ecs:ap-northeast-1:123456789012:task/stg/0123456789abcdef", StartedAt: started},
+			container: myecs.ECSContainer{ContainerName: "app", Image: "123456789012.dkr.ecr.ap-northeast-1.amazonaws.com/api:v1.2.3"},
+		},
+		{
+			region:    "ap-northeast-1",
+			cluster:   myecs.ECSCluster{ClusterName: "production"},
+			service:   myecs.ECSService{ServiceName: "worker"},
+			task:      myecs.ECSTask{TaskArn: "arn:aws:ecs:ap-northeast-1:123456789012:task/production/fedcba"},
+			container: myecs.ECSContainer{ContainerName: "app", Image: "nginx"},
+		},
+	}
+	now := started.Add(3*time.Hour + 12*time.Minute)
+
+	tests := []struct {
+		name   string
+		format string
+		want   []string
+	}{
+		{
+			name: "default",
+			want: []string{
+				"stg        api    app",
+				"production worker app",
+			},
+		},
+		{
+			name:   "fields and functions",
+			format: "{{.ShortTask}} {{.Image | repo}}:{{.Image | tag}} {{.Age}}",
+			want: []string{
+				"01234567 api:v1.2.3   3h12m",
+				"fedcba   nginx:latest",
+			},
+		},
+		{
+			name:   "colours are not counted in the width",
+			format: `{{if .Production}}{{color "31" .Cluster}}{{else}}{{.Cluster}}{{end}} {{.Service}}`,
+			want: []string{
+				"stg        api",
+				"\x1b[31mproduction\x1b[0m worker",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels, err := pickerLabels(tt.format, items, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, labels)
+		})
+	}
+
+	_, err := pickerLabels("{{.Cluster", items, now)
+	assert.ErrorContains(t, err, "invalid picker format")
+	_, err = pickerLabels("{{.Unknown}}", items, now)
+	assert.ErrorContains(t, err, "invalid picker format")
+}
+
+func TestSplitFormatFields(t *testing.T) {
+	assert.Equal(t,
+		[]string{"{{.Cluster}}", `{{if .Production}}{{color "1;31" "PROD"}}{{end}}`, "[{{.Zone}}]"},
+		splitFormatFields(`{{.Cluster}}  {{if .Production}}{{color "1;31" "PROD"}}{{end}} [{{.Zone}}]`))
+}
+
+func TestImageTagAndRepository(t *testing.T) {
+	tests := []struct {
+		image, tag, repo string
+	}{
+		{"nginx", "latest", "nginx"},
+		{"nginx:1.27", "1.27", "nginx"},
+		{"localhost:5000/team/api", "latest", "api"},
+		{"123456789012.dkr.ecr.ap-northeast-1.amazonaws.com/api:v1", "v1", "api"},
+		{"public.ecr.aws/datadog/agent@sha256:abc", "sha256:abc", "agent"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.tag, imageTag(tt.image), tt.image)
+		assert.Equal(t, tt.repo, config.ImageRepository(tt.image), tt.image)
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/policy"
+	"github.com/olekukonko/tablewriter"
+)
+
+// loadPolicy reads the policy file named in the config. Only the default
+// policy file may be missing, a policy file named in the config that does
+// not exist is an error rather than allowing everything.
+func loadPolicy() (*policy.Policy, error) {
+	path, err := appConfig.PolicyPath()
+	if err != nil {
+		return nil, err
+	}
+	p, err := policy.Load(path)
+	if err == nil && p == nil && appConfig.Policy != "" {
+		return nil, fmt.Errorf("policy file %s does not exist", path)
+	}
+	return p, err
+}
+
+func policyTarget(resource myecs.ECSResource) policy.Target {
+	cluster := resource.Clusters[0]
+	service := cluster.Services[0]
+	return policy.Target{
+		Cluster:   cluster.ClusterName,
+		Service:   service.ServiceName,
+		Container: service.Tasks[0].Containers[0].ContainerName,
+	}
+}
+
+// checkPolicy refuses to run command unless the policy allows it on every
+// target.
+func checkPolicy(p *policy.Policy, resources []myecs.ECSResource, command []string) error {
+	for _, resource := range resources {
+		if err := p.Check(policyTarget(resource), command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkLoginPolicy refuses to log in to resources unless the policy allows
+// the command named by --command, or the login shell when there is none.
+// interactive tells whether the shell reads its commands from a terminal.
+func checkLoginPolicy(resources []myecs.ECSResource, interactive bool) error {
+	p, err := loadPolicy()
+	if err != nil || p == nil {
+		return err
+	}
+	for _, resource := range resources {
+		spec := loginSpec(resource)
+		if spec.Command != "" {
+			err = p.Check(policyTarget(resource), []string{spec.Command})
+		} else {
+			err = p.CheckShell(policyTarget(resource), spec.Shell, interactive)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderPolicyCheck prints the policy decision for every target and fails
+// when any of them is denied.
+func renderPolicyCheck(w io.Writer, p *policy.Policy, resources []myecs.ECSResource, command []string) error {
+	table := tablewriter.NewTable(w,
+		tablewriter.WithHeader([]string{
+			"Target",
+			"Command",
+			"Decision",
+			"Rule",
+			"Reason"}))
+
+	denied := 0
+	for _, resource := range resources {
+		decision := p.Evaluate(policyTarget(resource), command)
+		result := "allow"
+		if !decision.Allowed {
+			result = "deny"
+			denied++
+		}
+		if err := table.Append([]string{
+			targetLabel(resource),
+			strings.Join(command, " "),
+			result,
+			decision.Rule,
+			decision.Reason,
+		}); err != nil {
+			return err
+		}
+	}
+	if err := table.Render(); err != nil {
+		return err
+	}
+
+	if denied > 0 {
+		return fmt.Errorf("%w on %d of %d targets", policy.ErrDenied, denied, len(resources))
+	}
+	return nil
+}
+
+func policyHint() string {
+	path, err := appConfig.PolicyPath()
+	if err != nil {
+		path = "the policy file"
+	}
+	return fmt.Sprintf("run the same command with --policy-check to see the deciding rule in %s", path)
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/config"
+	"github.com/jedipunkz/miniecs/internal/pkg/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPolicy(t *testing.T) {
+	p := &policy.Policy{Rules: []policy.Rule{
+		{Name: "prod", Cluster: "prod", Allow: []string{"cat *"}},
+	}}
+	resources := itemsToResources(testSelectableItems()[:4])
+
+	assert.NoError(t, checkPolicy(p, resources, []string{"cat", "REVISION"}))
+	assert.NoError(t, checkPolicy(nil, resources, []string{"env"}))
+
+	err := checkPolicy(p, resources, []string{"env"})
+	assert.True(t, errors.Is(err, policy.ErrDenied))
+	assert.Equal(t, exitPolicyDenied, exitCode(err))
+}
+
+func TestRenderPolicyCheck(t *testing.T) {
+	p := &policy.Policy{Rules: []policy.Rule{
+		{Name: "prod", Cluster: "prod", Allow: []string{"cat *"}},
+	}}
+	resources := itemsToResources(testSelectableItems()[:4])
+
+	var buf bytes.Buffer
+	err := renderPolicyCheck(&buf, p, resources, []string{"env"})
+	assert.ErrorContains(t, err, "on 3 of 4 targets")
+	assert.Contains(t, buf.String(), "prod/api/task1")
+	assert.Contains(t, buf.String(), "deny")
+	assert.Contains(t, buf.String(), "allow")
+
+	buf.Reset()
+	assert.NoError(t, renderPolicyCheck(&buf, p, resources, []string{"cat", "REVISION"}))
+}
+
+func TestLoadPolicyConfiguredMissing(t *testing.T) {
+	defer func(cfg *config.Config) { appConfig = cfg }(appConfig)
+	appConfig = config.Default()
+	appConfig.Policy = filepath.Join(t.TempDir(), "policy.yaml")
+
+	_, err := loadPolicy()
+	assert.ErrorContains(t, err, "does not exist")
+}
+
+func TestCheckLoginPolicy(t *testing.T) {
+	defer func(cfg *config.Config, command string) {
+		appConfig, loginSetFlags.command = cfg, command
+	}(appConfig, loginSetFlags.command)
+	appConfig = config.Default()
+	appConfig.Policy = filepath.Join(t.TempDir(), "policy.yaml")
+	assert.NoError(t, os.WriteFile(appConfig.Policy, []byte(`
+rules:
+  - cluster: prod
+    allow: ["bin/rails c"]
+  - cluster: stg
+    deny: ["rm *"]
+`), 0o600))
+
+	item := testSelectableItems()[0]
+	item.container.Commands = map[string]string{"console": "bin/rails c", "db": "bin/rails db"}
+	prod := []myecs.ECSResource{item.toResource()}
+
+	loginSetFlags.command = ""
+	err := checkLoginPolicy(prod, true)
+	assert.True(t, errors.Is(err, policy.ErrDenied))
+
+	loginSetFlags.command = "console"
+	assert.NoError(t, checkLoginPolicy(prod, true))
+	loginSetFlags.command = "db"
+	assert.True(t, errors.Is(checkLoginPolicy(prod, true), policy.ErrDenied))
+
+	loginSetFlags.command = ""
+	item.cluster.ClusterName = "stg"
+	stg := []myecs.ECSResource{item.toResource()}
+	assert.NoError(t, checkLoginPolicy(stg, true))
+	assert.True(t, errors.Is(checkLoginPolicy(stg, false), policy.ErrDenied))
+}
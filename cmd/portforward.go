@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type portForwardFlags struct {
+	region string
+	target targetFlags
+}
+
+var portForwardSetFlags portForwardFlags
+
+var portForwardCmd = &cobra.Command{
+	Use:   "port-forward [cluster/service/container | @bookmark] [local:]remote...",
+	Short: "forward local ports to a container",
+	Long: `Forward local ports to ports of a container through Session Manager.
+
+Each port is given as local:remote, or as remote alone to forward a free
+local port. Several ports can be forwarded at once, for example:
+
+  miniecs port-forward prod/api/app 8080:80 5005
+
+The ports are forwarded until interrupted with Ctrl-C.`,
+	Annotations: map[string]string{annotationMutating: "true"},
+	Args:        cobra.MinimumNArgs(1),
+	RunE:        runPortForwardCmd,
+}
+
+func runPortForwardCmd(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	targetArg, portArgs := splitPortForwardArgs(args)
+	forwards, err := parsePortForwards(portArgs)
+	if err != nil {
+		return err
+	}
+
+	target := portForwardSetFlags.target
+	if name, ok := bookmarkName(targetArg); ok {
+		bookmark, err := lookupBookmark(name)
+		if err != nil {
+			return err
+		}
+		target.applyBookmark(bookmark)
+		if portForwardSetFlags.region == "" {
+			portForwardSetFlags.region = bookmark.Region
+		}
+	} else if targetArg != "" {
+		if err := target.applyPath(targetArg); err != nil {
+			return err
+		}
+	}
+
+	region, err := resolveRegion(portForwardSetFlags.region)
+	if err != nil {
+		return err
+	}
+
+	ecsClient, err := initializeECSClient(ctx, region)
+	if err != nil {
+		return err
+	}
+
+	selectedResources, err := selectTargets(ctx, ecsClient, target)
+	if err != nil {
+		return err
+	}
+	if len(selectedResources) > 1 {
+		return fmt.Errorf("ports can be forwarded to one container only, %d were selected", len(selectedResources))
+	}
+	if err := confirmProduction(selectedResources); err != nil {
+		return err
+	}
+
+	resource := selectedResources[0]
+	return withService(forwardPorts(ctx, ecsClient, resource, forwards), resource)
+}
+
+// splitPortForwardArgs separates the optional target from the ports. The
+// first argument is the target unless it is a port itself.
+func splitPortForwardArgs(args []string) (targetArg string, portArgs []string) {
+	if !isPortForwardSpec(args[0]) {
+		return args[0], args[1:]
+	}
+	return "", args
+}
+
+func isPortForwardSpec(arg string) bool {
+	for _, port := range strings.SplitN(arg, ":", 2) {
+		if _, err := strconv.Atoi(port); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// freeLocalPort returns a local port nothing listens on.
+var freeLocalPort = func() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find a free local port: %w", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// parsePortForwards parses local:remote port pairs, choosing a free local
+// port for a remote port given alone.
+func parsePortForwards(specs []string) ([]myecs.PortForward, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no port given, expected [local:]remote")
+	}
+
+	var forwards []myecs.PortForward
+	seen := map[int]bool{}
+	for _, spec := range specs {
+		local, remote, paired := strings.Cut(spec, ":")
+		if !paired {
+			local, remote = "", local
+		}
+
+		var forward myecs.PortForward
+		var err error
+		if forward.RemotePort, err = parsePort(remote); err != nil {
+			return nil, fmt.Errorf("invalid port %q, expected [local:]remote: %w", spec, err)
+		}
+		if paired {
+			if forward.LocalPort, err = parsePort(local); err != nil {
+				return nil, fmt.Errorf("invalid port %q, expected [local:]remote: %w", spec, err)
+			}
+		} else if forward.LocalPort, err = freeLocalPort(); err != nil {
+			return nil, err
+		}
+
+		if seen[forward.LocalPort] {
+			return nil, fmt.Errorf("local port %d is given more than once", forward.LocalPort)
+		}
+		seen[forward.LocalPort] = true
+		forwards = append(forwards, forward)
+	}
+	return forwards, nil
+}
+
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if port < 1 || port > 65535 {
+		return 0, fmt.Errorf("port %d is out of range", port)
+	}
+	return port, nil
+}
+
+// forwardPorts runs a session-manager-plugin per port pair and waits until
+// all of them have exited.
+func forwardPorts(ctx context.Context, ecsClient *myecs.ECSResource, resource myecs.ECSResource, forwards []myecs.PortForward) error {
+	task, container := selectedTask(resource), selectedContainer(resource)
+	if task == nil || container == nil {
+		return fmt.Errorf("no container selected")
+	}
+	cluster := resource.Clusters[0].ClusterName
+
+	var sessions []*exec.Cmd
+	stopSessions := func() {
+		for _, session := range sessions {
+			_ = session.Process.Kill()
+			_ = session.Wait()
+		}
+	}
+	for _, forward := range forwards {
+		session, err := ecsClient.PortForwardCommand(ctx, cluster, task.TaskArn, *container, forward)
+		if err == nil {
+			// The sessions run side by side, none of them reads the terminal.
+			session.Stdin = nil
+			err = session.Start()
+		}
+		if err != nil {
+			stopSessions()
+			return err
+		}
+		sessions = append(sessions, session)
+		log.WithFields(log.Fields{
+			"cluster":   cluster,
+			"task":      taskID(task.TaskArn),
+			"container": container.ContainerName,
+		}).Infof("Forwarding localhost:%d to port %d", forward.LocalPort, forward.RemotePort)
+	}
+
+	errs := make(chan error, len(sessions))
+	for _, session := range sessions {
+		go func() { errs <- session.Wait() }()
+	}
+	var firstErr error
+	for range sessions {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("port forwarding session failed: %w", err)
+		}
+	}
+	return firstErr
+}
+
+func init() {
+	rootCmd.AddCommand(portForwardCmd)
+	portForwardCmd.Flags().StringVarP(
+		&portForwardSetFlags.region, "region", "", "", "Region Name (defaults to the region of --context)")
+	addTargetFlags(portForwardCmd, &portForwardSetFlags.target)
+}
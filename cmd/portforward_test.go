@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"testing"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitPortForwardArgs(t *testing.T) {
+	targetArg, ports := splitPortForwardArgs([]string{"prod/api/app", "8080:80", "5005"})
+	assert.Equal(t, "prod/api/app", targetArg)
+	assert.Equal(t, []string{"8080:80", "5005"}, ports)
+
+	targetArg, ports = splitPortForwardArgs([]string{"8080:80"})
+	assert.Empty(t, targetArg)
+	assert.Equal(t, []string{"8080:80"}, ports)
+
+	targetArg, ports = splitPortForwardArgs([]string{"@api-prod"})
+	assert.Equal(t, "@api-prod", targetArg)
+	assert.Empty(t, ports)
+}
+
+func TestParsePortForwards(t *testing.T) {
+	saved := freeLocalPort
+	t.Cleanup(func() { freeLocalPort = saved })
+	freeLocalPort = func() (int, error) { return 49152, nil }
+
+	forwards, err := parsePortForwards([]string{"8080:80", "5005"})
+	require.NoError(t, err)
+	assert.Equal(t, []myecs.PortForward{
+		{LocalPort: 8080, RemotePort: 80},
+		{LocalPort: 49152, RemotePort: 5005},
+	}, forwards)
+
+	for _, specs := range [][]string{
+		nil,
+		{"http"},
+		{"8080:"},
+		{"0:80"},
+		{"8080:70000"},
+		{"8080:80", "8080:443"},
+	} {
+		_, err := parsePortForwards(specs)
+		assert.Error(t, err, specs)
+	}
+}
+
+func TestFreeLocalPort(t *testing.T) {
+	port, err := freeLocalPort()
+	require.NoError(t, err)
+	assert.Positive(t, port)
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// itemPreview describes item in the preview window of the picker. Fields
+// that are not known are left out.
+func itemPreview(item selectableItem, now time.Time) string {
+	task, container := item.task, item.container
+
+	var preview strings.Builder
+	preview.WriteString(productionBanner(item.cluster.ClusterName))
+	line := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(&preview, "%-11s %s\n", label+":", value)
+		}
+	}
+
+	line("Cluster", item.cluster.ClusterName)
+	line("Service", item.service.ServiceName)
+	line("Container", container.ContainerName)
+	line("Task", taskID(task.TaskArn))
+	line("Status", taskStatus(task.LastStatus, task.DesiredStatus))
+	line("Health", task.HealthStatus)
+	if !task.StartedAt.IsZero() {
+		line("Started", fmt.Sprintf("%s (%s ago)",
+			task.StartedAt.Local().Format("2006-01-02 15:04:05"), formatAge(now.Sub(task.StartedAt))))
+	}
+	line("Zone", task.AvailabilityZone)
+	line("Private IP", task.PrivateIP)
+	line("Launch", launchType(task.LaunchType, task.CapacityProvider))
+	line("Task def", taskDefinitionName(task.TaskDefinition))
+	line("Image", container.Image)
+	line("Digest", container.ImageDigest)
+	line("CPU", withContainerLimit(task.CPU, containerCPU(container.CPU)))
+	memory := task.Memory
+	if memory != "" {
+		memory += " MiB"
+	}
+	line("Memory", withContainerLimit(memory, containerMemory(container.Memory, container.MemoryReservation)))
+	if task.TaskArn != "" {
+		line("Exec", execStatus(task.ExecEnabled, container.ExecAgentStatus))
+	}
+	return preview.String()
+}
+
+func taskStatus(last, desired string) string {
+	if desired == "" || desired == last {
+		return last
+	}
+	return fmt.Sprintf("%s (desired %s)", last, desired)
+}
+
+func launchType(launchType, capacityProvider string) string {
+	if capacityProvider != "" && capacityProvider != launchType {
+		if launchType == "" {
+			return capacityProvider
+		}
+		return fmt.Sprintf("%s (%s)", launchType, capacityProvider)
+	}
+	return launchType
+}
+
+// taskDefinitionName returns family:revision of a task definition ARN.
+func taskDefinitionName(taskDefinitionArn string) string {
+	return taskDefinitionArn[strings.LastIndex(taskDefinitionArn, "/")+1:]
+}
+
+// containerMemory returns the hard memory limit of a container, or its
+// soft limit marked as reserved.
+func containerMemory(memory, reservation int32) string {
+	switch {
+	case memory > 0:
+		return fmt.Sprintf("%d MiB", memory)
+	case reservation > 0:
+		return fmt.Sprintf("%d MiB reserved", reservation)
+	}
+	return ""
+}
+
+func containerCPU(cpu int32) string {
+	if cpu == 0 {
+		return ""
+	}
+	return fmt.Sprint(cpu)
+}
+
+// withContainerLimit formats the size of a task together with the limit of
+// the container, when it has one.
+func withContainerLimit(task, container string) string {
+	switch {
+	case container == "":
+		return task
+	case task == "":
+		return "container " + container
+	}
+	return fmt.Sprintf("%s (container %s)", task, container)
+}
+
+func execStatus(enabled bool, agentStatus string) string {
+	if !enabled {
+		return "disabled"
+	}
+	if agentStatus == "" {
+		return "enabled"
+	}
+	return fmt.Sprintf("enabled (agent %s)", agentStatus)
+}
+
+// formatAge formats d to the two largest units, like 3h12m or 2d4h.
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+}
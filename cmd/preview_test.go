@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemPreview(t *testing.T) {
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	item := selectableItem{
+		cluster: myecs.ECSCluster{ClusterName: "stg"},
+		service: myecs.ECSService{ServiceName: "api"},
+		task: myecs.ECSTask{
+			TaskArn:          "arn:aws:ecs:ap-northeast-1:123456789012:task/stg/0123abcd",
+			TaskDefinition:   "arn:aws:ecs:ap-northeast-1:123456789012:task-definition/api:42",
+			LastStatus:       "RUNNING",
+			DesiredStatus:    "STOPPED",
+			HealthStatus:     "HEALTHY",
+			StartedAt:        started,
+			AvailabilityZone: "ap-northeast-1a",
+			PrivateIP:        "10.0.1.23",
+			LaunchType:       "FARGATE",
+			CPU:              "512",
+			Memory:           "1024",
+			ExecEnabled:      true,
+		},
+		container: myecs.ECSContainer{
+			ContainerName:     "app",
+			Image:             "123456789012.dkr.ecr.ap-northeast-1.amazonaws.com/api:v1.2.3",
+			ImageDigest:       "sha256:abcdef",
+			CPU:               256,
+			MemoryReservation: 512,
+			ExecAgentStatus:   "RUNNING",
+		},
+	}
+
+	preview := itemPreview(item, started.Add(3*time.Hour+12*time.Minute))
+	for _, want := range []string{
+		"Cluster:    stg\n",
+		"Task:       0123abcd\n",
+		"Status:     RUNNING (desired STOPPED)\n",
+		"Health:     HEALTHY\n",
+		"(3h12m ago)\n",
+		"Zone:       ap-northeast-1a\n",
+		"Private IP: 10.0.1.23\n",
+		"Launch:     FARGATE\n",
+		"Task def:   api:42\n",
+		"Image:      123456789012.dkr.ecr.ap-northeast-1.amazonaws.com/api:v1.2.3\n",
+		"Digest:     sha256:abcdef\n",
+		"CPU:        512 (container 256)\n",
+		"Memory:     1024 MiB (container 512 MiB reserved)\n",
+		"Exec:       enabled (agent RUNNING)\n",
+	} {
+		assert.Contains(t, preview, want)
+	}
+
+	preview = itemPreview(selectableItem{cluster: myecs.ECSCluster{ClusterName: "stg"}}, started)
+	assert.Equal(t, "Cluster:    stg\n", preview)
+}
+
+func TestFormatAge(t *testing.T) {
+	assert.Equal(t, "42s", formatAge(42*time.Second))
+	assert.Equal(t, "5m", formatAge(5*time.Minute+30*time.Second))
+	assert.Equal(t, "3h12m", formatAge(3*time.Hour+12*time.Minute))
+	assert.Equal(t, "2d4h", formatAge(52*time.Hour))
+}
+
+func TestLaunchType(t *testing.T) {
+	assert.Equal(t, "FARGATE", launchType("FARGATE", ""))
+	assert.Equal(t, "FARGATE (FARGATE_SPOT)", launchType("FARGATE", "FARGATE_SPOT"))
+	assert.Equal(t, "my-capacity", launchType("", "my-capacity"))
+}
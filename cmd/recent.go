@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/cache"
+	log "github.com/sirupsen/logrus"
+)
+
+// loadRecentTargets is a variable so that tests can use a temporary
+// history.
+var loadRecentTargets = cache.LoadRecentTargets
+
+// lastTarget returns the most recent login of account, in region unless
+// region is empty.
+func lastTarget(region, account string) (cache.RecentTarget, error) {
+	recent, err := loadRecentTargets()
+	if err != nil {
+		return cache.RecentTarget{}, err
+	}
+	target, ok := recent.Last(func(t cache.RecentTarget) bool {
+		return (region == "" || t.Region == region) &&
+			(account == "" || t.Account == "" || t.Account == account)
+	})
+	if !ok {
+		if region != "" {
+			return cache.RecentTarget{}, fmt.Errorf("no previous login in %s to return to", region)
+		}
+		return cache.RecentTarget{}, fmt.Errorf("no previous login to return to")
+	}
+	return target, nil
+}
+
+// lastRegion is the region of the most recent login, used to look up the
+// caller identity before the last target is known.
+func lastRegion() string {
+	recent, err := loadRecentTargets()
+	if err != nil || len(recent.Targets) == 0 {
+		return ""
+	}
+	return recent.Targets[0].Region
+}
+
+// resolveLastLogin connects to the region of the last login and returns the
+// container of it in a currently running task.
+func resolveLastLogin(ctx context.Context, region string, t targetFlags) (*myecs.ECSResource, []myecs.ECSResource, error) {
+	lookupRegion := region
+	if lookupRegion == "" {
+		lookupRegion = lastRegion()
+	}
+	if lookupRegion == "" {
+		return nil, nil, fmt.Errorf("no previous login to return to")
+	}
+
+	ecsClient, err := initializeECSClient(ctx, lookupRegion)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, account := sessionIdentity.get()
+
+	last, err := lastTarget(region, account)
+	if err != nil {
+		return nil, nil, err
+	}
+	if last.Region != lookupRegion {
+		if ecsClient, err = initializeECSClient(ctx, last.Region); err != nil {
+			return nil, nil, err
+		}
+	}
+	log.Infof("Logging in to %s/%s/%s in %s again", last.Cluster, last.Service, last.Container, last.Region)
+
+	t.cluster, t.service, t.container, t.task = last.Cluster, last.Service, last.Container, ""
+	t.pickRunning = true
+	selectedResources, err := selectTargets(ctx, ecsClient, t)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ecsClient, selectedResources, nil
+}
+
+// rememberOnSession adds the container of resource to the login history
+// as soon as a session to it has started, so that sessions which are
+// killed or still running are remembered too. Logins that fail to start or
+// are declined are not remembered.
+func rememberOnSession(ecsClient *myecs.ECSResource, resource myecs.ECSResource) {
+	task, container := selectedTask(resource), selectedContainer(resource)
+	if task == nil || container == nil {
+		return
+	}
+	remembered := false
+	previous := ecsClient.OnSessionStart
+	ecsClient.OnSessionStart = func(session myecs.Session) {
+		if previous != nil {
+			previous(session)
+		}
+		if remembered || session.Task != task.TaskArn || session.Container != container.ContainerName {
+			return
+		}
+		remembered = true
+		rememberTargets(ecsClient.Region, []myecs.ECSResource{resource})
+	}
+}
+
+// rememberTargets adds the containers logged in to to the login history.
+func rememberTargets(region string, resources []myecs.ECSResource) {
+	recent, err := loadRecentTargets()
+	if err != nil {
+		log.Debugf("login history not updated: %v", err)
+		return
+	}
+	for _, resource := range resources {
+		cluster := resource.Clusters[0]
+		service := cluster.Services[0]
+		task := service.Tasks[0]
+		recent.Add(cache.RecentTarget{
+			Region:    region,
+			Account:   arnAccount(task.TaskArn),
+			Cluster:   cluster.ClusterName,
+			Service:   service.ServiceName,
+			Container: task.Containers[0].ContainerName,
+		})
+	}
+	if err := recent.Save(); err != nil {
+		log.Debugf("login history not updated: %v", err)
+	}
+}
+
+// arnAccount returns the account ID of an ARN, empty when arn has none.
+func arnAccount(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 {
+		return ""
+	}
+	return parts[4]
+}
+
+// rankByHistory orders items by how often and how recently they were
+// logged in to, keeping the order of items never used.
+func rankByHistory(items []selectableItem, region string) []selectableItem {
+	recent, err := loadRecentTargets()
+	if err != nil || len(recent.Targets) == 0 {
+		return items
+	}
+
+	scores := make(map[string]float64, len(items))
+	for _, item := range items {
+		key := itemHistoryKey(region, item)
+		if _, ok := scores[key]; ok {
+			continue
+		}
+		scores[key] = recent.Score(func(t cache.RecentTarget) bool {
+			return historyKey(t.Region, t.Cluster, t.Service, t.Container) == key
+		})
+	}
+
+	ranked := append([]selectableItem(nil), items...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[itemHistoryKey(region, ranked[i])] > scores[itemHistoryKey(region, ranked[j])]
+	})
+	return ranked
+}
+
+func historyKey(region, cluster, service, container string) string {
+	return region + "|" + cluster + "|" + service + "|" + container
+}
+
+func itemHistoryKey(region string, item selectableItem) string {
+	return historyKey(region, item.cluster.ClusterName, item.service.ServiceName, item.container.ContainerName)
+}
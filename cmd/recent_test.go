@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withRecentTargets(t *testing.T, targets ...cache.RecentTarget) {
+	t.Helper()
+	saved := loadRecentTargets
+	t.Cleanup(func() { loadRecentTargets = saved })
+	loadRecentTargets = func() (*cache.RecentTargets, error) {
+		return &cache.RecentTargets{Targets: targets}, nil
+	}
+}
+
+func TestLastTarget(t *testing.T) {
+	withRecentTargets(t,
+		cache.RecentTarget{Region: "us-east-1", Account: "111111111111", Cluster: "dev", Service: "api", Container: "app"},
+		cache.RecentTarget{Region: "ap-northeast-1", Account: "222222222222", Cluster: "prod", Service: "api", Container: "app"},
+		cache.RecentTarget{Region: "ap-northeast-1", Cluster: "stg", Service: "api", Container: "app"},
+	)
+
+	last, err := lastTarget("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "dev", last.Cluster)
+
+	last, err = lastTarget("ap-northeast-1", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", last.Cluster)
+
+	last, err = lastTarget("", "333333333333")
+	assert.NoError(t, err)
+	assert.Equal(t, "stg", last.Cluster)
+
+	_, err = lastTarget("eu-west-1", "")
+	assert.ErrorContains(t, err, "no previous login in eu-west-1")
+
+	assert.Equal(t, "us-east-1", lastRegion())
+}
+
+func TestRankByHistory(t *testing.T) {
+	items := testSelectableItems()
+	withRecentTargets(t,
+		cache.RecentTarget{Region: "ap-northeast-1", Cluster: "stg", Service: "api", Container: "app", Count: 3, LastUsed: time.Now()},
+		cache.RecentTarget{Region: "ap-northeast-1", Cluster: "prod", Service: "worker", Container: "app", Count: 1, LastUsed: time.Now()},
+		cache.RecentTarget{Region: "us-east-1", Cluster: "prod", Service: "api", Container: "app", Count: 9, LastUsed: time.Now()},
+	)
+
+	ranked := rankByHistory(items, "ap-northeast-1")
+	assert.Len(t, ranked, len(items))
+	assert.Equal(t, "stg", ranked[0].cluster.ClusterName)
+	assert.Equal(t, "worker", ranked[1].service.ServiceName)
+	// never used items keep their order
+	assert.Equal(t, items[0], ranked[2])
+	assert.Equal(t, items[1], ranked[3])
+}
+
+func TestRememberOnSession(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	saved := loadRecentTargets
+	t.Cleanup(func() { loadRecentTargets = saved })
+	loadRecentTargets = cache.LoadRecentTargets
+
+	taskArn := "arn:aws:ecs:ap-northeast-1:123456789012:task/prod/task1"
+	resource := selectableItem{
+		cluster:   myecs.ECSCluster{ClusterName: "prod"},
+		service:   myecs.ECSService{ServiceName: "api"},
+		task:      myecs.ECSTask{TaskArn: taskArn},
+		container: myecs.ECSContainer{ContainerName: "app"},
+	}.toResource()
+
+	var started []myecs.Session
+	ecsClient := &myecs.ECSResource{Region: "ap-northeast-1"}
+	ecsClient.OnSessionStart = func(session myecs.Session) { started = append(started, session) }
+	rememberOnSession(ecsClient, resource)
+
+	// other containers are not remembered
+	ecsClient.OnSessionStart(myecs.Session{Task: taskArn, Container: "envoy", SessionID: "s1"})
+	_, err := lastTarget("", "")
+	assert.Error(t, err)
+
+	// the session is remembered while it is still running
+	ecsClient.OnSessionStart(myecs.Session{Task: taskArn, Container: "app", SessionID: "s2"})
+	last, err := lastTarget("ap-northeast-1", "123456789012")
+	require.NoError(t, err)
+	assert.Equal(t, cache.RecentTarget{
+		Region: "ap-northeast-1", Account: "123456789012", Cluster: "prod", Service: "api", Container: "app",
+	}, cache.RecentTarget{
+		Region: last.Region, Account: last.Account, Cluster: last.Cluster, Service: last.Service, Container: last.Container,
+	})
+	assert.Len(t, started, 2, "hooks added before keep running")
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/record"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/term"
+)
+
+// checkRecord rejects --record where there is no single interactive
+// session to record.
+func checkRecord(path string, tmux bool) error {
+	if path == "" {
+		return nil
+	}
+	if tmux {
+		return fmt.Errorf("--record cannot be combined with --tmux")
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("--record needs an interactive terminal")
+	}
+	return nil
+}
+
+// recordLogin runs the login session in a pseudo terminal and writes it to
+// path as an asciicast v2 file.
+func recordLogin(ecsClient *myecs.ECSResource, input ecs.ExecuteCommandInput, resource myecs.ECSResource, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create recording: %w", err)
+	}
+	defer f.Close()
+
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+	rec, err := record.NewWriter(f, record.Header{
+		Width:  width,
+		Height: height,
+		Title:  targetLabel(resource),
+		Env: map[string]string{
+			"SHELL": getShell(resource),
+			"TERM":  os.Getenv("TERM"),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if secretRedactor != nil {
+		rec.Redact(secretRedactor.String)
+	}
+
+	err = ecsClient.ExecuteCommandRecorded(input, rec)
+	if closeErr := rec.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		log.Infof("Session recorded to %s", path)
+	}
+	return err
+}
@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRecord(t *testing.T) {
+	assert.NoError(t, checkRecord("", true))
+	assert.ErrorContains(t, checkRecord("out.cast", true), "--tmux")
+	// go test does not run with a terminal on stdin
+	assert.ErrorContains(t, checkRecord("out.cast", false), "interactive terminal")
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/config"
+	"github.com/jedipunkz/miniecs/internal/pkg/redact"
+)
+
+// secretRedactor masks secrets in everything miniecs writes to disk or
+// captures from a session. It is nil when redaction is disabled.
+var secretRedactor *redact.Redactor
+
+func newRedactor(cfg config.RedactConfig) (*redact.Redactor, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	return redact.New(cfg.Patterns)
+}
+
+// registerSecrets masks the values of the task definition secrets of the
+// selected containers.
+func registerSecrets(resources []myecs.ECSResource) {
+	for _, resource := range resources {
+		if container := selectedContainer(resource); container != nil {
+			secretRedactor.AddSecretNames(container.SecretNames...)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jedipunkz/miniecs/internal/pkg/record"
+	"github.com/spf13/cobra"
+)
+
+var replaySetFlags struct {
+	speed     float64
+	idleLimit time.Duration
+}
+
+var replayCmd = &cobra.Command{
+	Use:   "replay file.cast",
+	Short: "replay a session recorded with login --record",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runReplayCmd,
+}
+
+func runReplayCmd(cmd *cobra.Command, args []string) error {
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer f.Close()
+
+	header, events, err := record.Read(f)
+	if err != nil {
+		return err
+	}
+
+	idleLimit := replaySetFlags.idleLimit
+	if idleLimit == 0 && header.IdleTimeLimit > 0 {
+		idleLimit = time.Duration(header.IdleTimeLimit * float64(time.Second))
+	}
+	return record.Replay(os.Stdout, events, record.ReplayOptions{
+		Speed:     replaySetFlags.speed,
+		IdleLimit: idleLimit,
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+	replayCmd.Flags().Float64VarP(
+		&replaySetFlags.speed, "speed", "s", 1, "Playback speed")
+	replayCmd.Flags().DurationVarP(
+		&replaySetFlags.idleLimit, "idle-limit", "", 0, "Longest pause between two outputs, e.g. 2s")
+}
@@ -1,13 +1,20 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 
+	"github.com/jedipunkz/miniecs/internal/pkg/config"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 var osExit = os.Exit
 
+// appConfig holds the config file settings, loaded before any subcommand
+// runs.
+var appConfig = config.Default()
+
 var rootCmd = &cobra.Command{
 	Use:   "miniecs",
 	Short: "A brief description of your application",
@@ -17,12 +24,36 @@ examples and usage of using your application. For example:
 Cobra is a CLI library for Go that empowers applications.
 This application is a tool to generate the needed files
 to quickly create a Cobra application.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		appConfig = cfg
+		if secretRedactor, err = newRedactor(cfg.Redact); err != nil {
+			return err
+		}
+		return checkReadonly(cmd)
+	},
 }
 
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		osExit(1)
+		reportError(err)
+		osExit(exitCode(err))
+	}
+}
+
+func reportError(err error) {
+	if exitCode(err) == exitCancelled || isRemoteExit(err) {
+		return
+	}
+	log.Error(err)
+	if hint := hint(err); hint != "" {
+		fmt.Fprintf(os.Stderr, "hint: %s\n", hint)
 	}
 }
 
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/jedipunkz/miniecs/internal/pkg/cache"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// detectShell fills in the shell of the selected container, probing the
+// container on the first login to its image and caching the result by image
+// digest. Containers without a digest are probed on every login. Failures
+// fall back to the default shell.
+func detectShell(ecsClient *myecs.ECSResource, resource myecs.ECSResource) {
+	container := selectedContainer(resource)
+	if loginSetFlags.shell != "" || container == nil || container.Shell != "" {
+		return
+	}
+	// The probe is a POSIX shell script, Windows containers always use
+	// PowerShell.
+	if isWindows(resource) {
+		return
+	}
+
+	// Tags such as app:latest move to other images, only digests are
+	// cached.
+	key := container.ImageDigest
+
+	var shellCache *cache.ShellCache
+	if key != "" {
+		var err error
+		if shellCache, err = cache.LoadShellCache(); err != nil {
+			log.Warn(err)
+		}
+	}
+	if shellCache != nil {
+		if shell, ok := shellCache.Get(key); ok {
+			container.Shell = shell
+			return
+		}
+	}
+
+	log.WithField("container", container.ContainerName).Info("Detecting login shell")
+	shell, err := ecsClient.DetectShell(createExecuteCommandInput(resource))
+	if err != nil {
+		log.Warnf("%v, falling back to %s", err, getShell(resource))
+		return
+	}
+	container.Shell = shell
+
+	if shellCache != nil {
+		shellCache.Set(key, shell)
+		if err := shellCache.Save(); err != nil {
+			log.Warn(err)
+		}
+	}
+}
+
+// sessionFlags choose who runs the session and where it starts. They
+// override the miniecs.user and miniecs.workdir docker labels.
+type sessionFlags struct {
+	user    string
+	workdir string
+	env     []string
+}
+
+func addSessionFlags(cmd *cobra.Command, s *sessionFlags) {
+	cmd.Flags().StringVarP(
+		&s.user, "user", "u", "", "Run as this user instead of the image default")
+	cmd.Flags().StringVarP(
+		&s.workdir, "workdir", "w", "", "Start in this working directory")
+	cmd.Flags().StringArrayVarP(
+		&s.env, "env", "e", nil, "Set an environment variable, KEY=VALUE (repeatable)")
+}
+
+// sessionSpec returns the user, working directory and platform for a
+// session in the selected container of resource.
+func sessionSpec(resource myecs.ECSResource, flags sessionFlags) myecs.LoginSpec {
+	spec := myecs.LoginSpec{Windows: isWindows(resource)}
+	if container := selectedContainer(resource); container != nil {
+		spec.User = container.User
+		spec.WorkDir = container.WorkDir
+	}
+	if flags.user != "" {
+		spec.User = flags.user
+	}
+	if flags.workdir != "" {
+		spec.WorkDir = flags.workdir
+	}
+	spec.Env = flags.env
+	return spec
+}
+
+type promptData struct {
+	Region    string
+	Cluster   string
+	Service   string
+	Task      string
+	Container string
+}
+
+// renderPrompt renders the configured prompt template for resource and
+// returns it with the colour for the kind of cluster it runs in.
+func renderPrompt(resource myecs.ECSResource, region string) (prompt, color string, err error) {
+	promptConfig := appConfig.Prompt
+	if promptConfig.Template == "" {
+		return "", "", nil
+	}
+
+	tmpl, err := template.New("prompt").Parse(promptConfig.Template)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid prompt template: %w", err)
+	}
+
+	data := promptData{Region: region}
+	if len(resource.Clusters) > 0 {
+		data.Cluster = resource.Clusters[0].ClusterName
+		if len(resource.Clusters[0].Services) > 0 {
+			data.Service = resource.Clusters[0].Services[0].ServiceName
+		}
+	}
+	if task := selectedTask(resource); task != nil {
+		data.Task = taskID(task.TaskArn)
+	}
+	if container := selectedContainer(resource); container != nil {
+		data.Container = container.ContainerName
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", "", fmt.Errorf("invalid prompt template: %w", err)
+	}
+
+	color = promptConfig.Color
+	if isProduction(data.Cluster) {
+		color = promptConfig.ProductionColor
+	}
+	return b.String(), color, nil
+}
+
+// remoteCommand quotes argv for the shell of the platform resource runs on.
+func remoteCommand(resource myecs.ECSResource, argv []string) string {
+	if isWindows(resource) {
+		return myecs.PowerShellJoin(argv)
+	}
+	return myecs.ShellJoin(argv)
+}
+
+func isWindows(resource myecs.ECSResource) bool {
+	task := selectedTask(resource)
+	return task != nil && task.IsWindows()
+}
+
+// selectedTask returns the task a selected resource points at.
+func selectedTask(resource myecs.ECSResource) *myecs.ECSTask {
+	if len(resource.Clusters) == 0 || len(resource.Clusters[0].Services) == 0 {
+		return nil
+	}
+	tasks := resource.Clusters[0].Services[0].Tasks
+	if len(tasks) == 0 {
+		return nil
+	}
+	return &tasks[0]
+}
+
+// selectedContainer returns the container a selected resource points at.
+func selectedContainer(resource myecs.ECSResource) *myecs.ECSContainer {
+	task := selectedTask(resource)
+	if task == nil || len(task.Containers) == 0 {
+		return nil
+	}
+	return &task.Containers[0]
+}
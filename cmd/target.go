@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/spf13/cobra"
+)
+
+// targetFlags narrows down the containers offered for selection. Any field
+// left empty matches every value.
+type targetFlags struct {
+	cluster   string
+	service   string
+	task      string
+	container string
+	sidecars  bool
+	// pick is the --pick strategy choosing one of several matching tasks.
+	pick string
+	// picker overrides the picker backend of the config.
+	picker string
+	// drill picks the cluster, service, task and container one at a time.
+	drill bool
+	// actions accepts the selection with the keys of targetActions.
+	actions bool
+	// pickRunning chooses a running task instead of asking, for targets
+	// remembered by service such as bookmarks.
+	pickRunning bool
+}
+
+func addTargetFlags(cmd *cobra.Command, t *targetFlags) {
+	cmd.Flags().StringVarP(
+		&t.cluster, "cluster", "", "", "ECS Cluster Name")
+	cmd.Flags().StringVarP(
+		&t.service, "service", "", "", "ECS Service Name")
+	cmd.Flags().StringVarP(
+		&t.task, "task", "", "", "ECS Task ARN or ID")
+	cmd.Flags().StringVarP(
+		&t.container, "container", "", "", "Container Name")
+	cmd.Flags().BoolVarP(
+		&t.sidecars, "sidecars", "", false, "Include sidecar containers")
+	cmd.Flags().StringVarP(
+		&t.pick, "pick", "", "", "Choose a task instead of asking: newest, oldest, random, least-loaded or az=<zone>")
+	cmd.Flags().BoolVarP(
+		&t.drill, "drill", "", false, "Pick the cluster, service, task and container one level at a time")
+	cmd.Flags().StringVarP(
+		&t.picker, "picker", "", "", "Picker backend: builtin, fzf, sk or prompt")
+}
+
+// applyPath fills t from a "cluster/service/container" target path. Empty
+// segments keep the value given by flags.
+func (t *targetFlags) applyPath(path string) error {
+	parts := strings.Split(path, "/")
+	if len(parts) > 3 {
+		return fmt.Errorf("invalid target %q, expected cluster/service/container", path)
+	}
+
+	fields := []*string{&t.cluster, &t.service, &t.container}
+	for i, part := range parts {
+		if part != "" {
+			*fields[i] = part
+		}
+	}
+	return nil
+}
+
+func (t targetFlags) matches(item selectableItem) bool {
+	// Containers labelled miniecs.hidden are only reachable by name.
+	if item.container.Hidden && t.container != item.container.ContainerName {
+		return false
+	}
+	if t.cluster != "" && t.cluster != item.cluster.ClusterName {
+		return false
+	}
+	if t.service != "" && t.service != item.service.ServiceName {
+		return false
+	}
+	if t.task != "" && t.task != item.task.TaskArn && t.task != taskID(item.task.TaskArn) {
+		return false
+	}
+	if t.container != "" && t.container != item.container.ContainerName {
+		return false
+	}
+	return true
+}
+
+func filterSelectableItems(items []selectableItem, t targetFlags) []selectableItem {
+	var filtered []selectableItem
+	for _, item := range items {
+		if t.matches(item) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// selectTargets resolves t to containers, showing the picker only when more
+// than one container matches.
+func selectTargets(ctx context.Context, ecsClient *myecs.ECSResource, t targetFlags) ([]myecs.ECSResource, error) {
+	resources, _, err := selectTargetsWithAction(ctx, ecsClient, t)
+	return resources, err
+}
+
+// selectTargetsWithAction is selectTargets also returning the action chosen
+// with a key in the picker when t.actions is set, empty for the default.
+func selectTargetsWithAction(ctx context.Context, ecsClient *myecs.ECSResource, t targetFlags) ([]myecs.ECSResource, string, error) {
+	if err := validatePick(t.pick); err != nil {
+		return nil, "", err
+	}
+	p, err := newPicker(t.picker)
+	if err != nil {
+		return nil, "", err
+	}
+	items, err := matchTargets(ctx, ecsClient, t)
+	if err != nil {
+		return nil, "", err
+	}
+	if t.pick != "" {
+		if items, err = pickTask(ctx, ecsClient, t.primaryItems(items), t.pick); err != nil {
+			return nil, "", err
+		}
+	}
+	if primary := t.primaryItems(items); len(primary) == 1 || t.pickRunning {
+		return []myecs.ECSResource{preferRunning(primary).toResource()}, "", nil
+	}
+	return pickItems(p, rankByHistory(items, ecsClient.Region), t.showSidecars(), t.drill, t.actions)
+}
+
+// preferRunning returns the first item of a running task, or the first item
+// when no task is running.
+func preferRunning(items []selectableItem) selectableItem {
+	for _, item := range items {
+		if item.task.LastStatus == "RUNNING" {
+			return item
+		}
+	}
+	return items[0]
+}
+
+// matchTargets returns every container matching t.
+func matchTargets(ctx context.Context, ecsClient *myecs.ECSResource, t targetFlags) ([]selectableItem, error) {
+	ecsResources, err := fetchAllECSResources(ctx, ecsClient, t.cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	items := filterSelectableItems(buildSelectableItems(ecsResources), t)
+	if len(items) == 0 {
+		return nil, &myecs.Error{Kind: myecs.ErrNoResources, Cluster: t.cluster, Service: t.service}
+	}
+	for i := range items {
+		items[i].region = ecsClient.Region
+	}
+	return items, nil
+}
+
+func (t targetFlags) showSidecars() bool {
+	return t.sidecars || t.container != "" || appConfig.Sidecars.Show
+}
+
+// primaryItems drops sidecar containers from items unless they were asked
+// for, or unless nothing but sidecars matched.
+func (t targetFlags) primaryItems(items []selectableItem) []selectableItem {
+	if t.showSidecars() {
+		return items
+	}
+	primary := withoutSidecars(items)
+	if len(primary) == 0 {
+		return items
+	}
+	return primary
+}
+
+func withoutSidecars(items []selectableItem) []selectableItem {
+	var filtered []selectableItem
+	for _, item := range items {
+		if !item.isSidecar() {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+func (item selectableItem) isSidecar() bool {
+	return appConfig.Sidecars.IsSidecar(item.container.ContainerName, item.container.Image, item.container.Essential)
+}
+
+func itemsToResources(items []selectableItem) []myecs.ECSResource {
+	resources := make([]myecs.ECSResource, len(items))
+	for i, item := range items {
+		resources[i] = item.toResource()
+	}
+	return resources
+}
+
+// firstArg returns args[0], or "" when there are no args.
+func firstArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+func taskID(taskArn string) string {
+	return taskArn[strings.LastIndex(taskArn, "/")+1:]
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"testing"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSelectableItems() []selectableItem {
+	newItem := func(cluster, service, task, container string) selectableItem {
+		return selectableItem{
+			cluster:   myecs.ECSCluster{ClusterName: cluster},
+			service:   myecs.ECSService{ServiceName: service},
+			task:      myecs.ECSTask{TaskArn: "arn:aws:ecs:ap-northeast-1:123456789012:task/" + cluster + "/" + task},
+			container: myecs.ECSContainer{ContainerName: container, Essential: true},
+		}
+	}
+	hidden := newItem("prod", "api", "task1", "log_router")
+	hidden.container.Hidden = true
+	return []selectableItem{
+		newItem("prod", "api", "task1", "app"),
+		newItem("prod", "api", "task1", "envoy"),
+		newItem("prod", "worker", "task2", "app"),
+		newItem("stg", "api", "task3", "app"),
+		hidden,
+	}
+}
+
+func TestTargetFlagsApplyPath(t *testing.T) {
+	target := targetFlags{cluster: "stg", container: "envoy"}
+	assert.NoError(t, target.applyPath("prod/api"))
+	assert.Equal(t, targetFlags{cluster: "prod", service: "api", container: "envoy"}, target)
+
+	assert.Error(t, target.applyPath("a/b/c/d"))
+}
+
+func TestFilterSelectableItems(t *testing.T) {
+	items := testSelectableItems()
+
+	tests := []struct {
+		name     string
+		target   targetFlags
+		expected int
+	}{
+		{name: "no filter", target: targetFlags{}, expected: 4},
+		{name: "cluster", target: targetFlags{cluster: "prod"}, expected: 3},
+		{name: "service and container", target: targetFlags{service: "api", container: "app"}, expected: 2},
+		{name: "task id", target: targetFlags{task: "task2"}, expected: 1},
+		{name: "no match", target: targetFlags{cluster: "dev"}, expected: 0},
+		{name: "hidden by name", target: targetFlags{container: "log_router"}, expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, filterSelectableItems(items, tt.target), tt.expected)
+		})
+	}
+}
+
+func TestPrimaryItems(t *testing.T) {
+	items := testSelectableItems()
+
+	// envoy is a sidecar by name, log_router is hidden
+	primary := targetFlags{}.primaryItems(filterSelectableItems(items, targetFlags{service: "api", cluster: "prod"}))
+	assert.Len(t, primary, 1)
+	assert.Equal(t, "app", primary[0].container.ContainerName)
+
+	all := targetFlags{sidecars: true}.primaryItems(filterSelectableItems(items, targetFlags{service: "api", cluster: "prod"}))
+	assert.Len(t, all, 2)
+
+	onlySidecars := filterSelectableItems(items, targetFlags{container: "envoy"})
+	assert.Len(t, targetFlags{}.primaryItems(onlySidecars), 1)
+}
+
+func TestPreferRunning(t *testing.T) {
+	items := []selectableItem{
+		{task: myecs.ECSTask{TaskArn: "task1", LastStatus: "PROVISIONING"}},
+		{task: myecs.ECSTask{TaskArn: "task2", LastStatus: "RUNNING"}},
+	}
+	assert.Equal(t, "task2", preferRunning(items).task.TaskArn)
+	assert.Equal(t, "task1", preferRunning(items[:1]).task.TaskArn)
+}
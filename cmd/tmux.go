@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	tmuxPanes   = "panes"
+	tmuxWindows = "windows"
+)
+
+type tmuxTarget struct {
+	title   string
+	command string
+	// sessionID is the session command attaches to.
+	sessionID string
+}
+
+// tmuxCommand runs tmux with args and returns its trimmed stdout. It is a
+// variable so that tests can record the invocations.
+var tmuxCommand = func(args ...string) (string, error) {
+	out, err := exec.Command("tmux", args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("tmux %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// terminateSession ends a session that was started but will never be
+// attached to. It is a variable so that tests can record the calls.
+var terminateSession = func(ecsClient *myecs.ECSResource, sessionID string) error {
+	return ecsClient.TerminateSession(context.Background(), sessionID)
+}
+
+// tmuxAttach attaches the current terminal to session when miniecs was not
+// started from inside tmux.
+var tmuxAttach = func(session string) error {
+	cmd := exec.Command("tmux", "attach-session", "-t", session)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// executeTmuxLogin opens one session-manager-plugin per resource in tmux
+// panes or windows titled with cluster/service/task.
+func executeTmuxLogin(ecsClient *myecs.ECSResource, resources []myecs.ECSResource, layout string, synchronize bool) error {
+	if layout != tmuxPanes && layout != tmuxWindows {
+		return fmt.Errorf("invalid tmux layout %q, expected %s or %s", layout, tmuxPanes, tmuxWindows)
+	}
+	if _, err := exec.LookPath("tmux"); err != nil {
+		return fmt.Errorf("tmux is not installed: %w", err)
+	}
+
+	if err := checkLoginPolicy(resources, true); err != nil {
+		return err
+	}
+	if err := confirmProduction(resources); err != nil {
+		return err
+	}
+
+	var targets []tmuxTarget
+	for _, resource := range resources {
+		if err := checkLoginCommand(resource); err != nil {
+			return endTmuxSessions(ecsClient, targets, err)
+		}
+		detectShell(ecsClient, resource)
+		rememberOnSession(ecsClient, resource)
+		sessionCmd, sessionID, err := ecsClient.SessionCommand(createExecuteCommandInput(resource))
+		if err != nil {
+			return endTmuxSessions(ecsClient, targets, withService(err, resource))
+		}
+		targets = append(targets, tmuxTarget{
+			title:     targetLabel(resource),
+			command:   myecs.ShellJoin(sessionCmd.Args),
+			sessionID: sessionID,
+		})
+	}
+
+	session := ""
+	if os.Getenv("TMUX") == "" {
+		session = fmt.Sprintf("miniecs-%d", os.Getpid())
+	}
+	if err := openTmuxTargets(targets, layout, synchronize, session); err != nil {
+		return endTmuxSessions(ecsClient, targets, err)
+	}
+	if session != "" {
+		return tmuxAttach(session)
+	}
+	return nil
+}
+
+// endTmuxSessions terminates the sessions started for targets when tmux
+// could not be set up for all of them, so that none is left open on the
+// tasks, and returns err.
+func endTmuxSessions(ecsClient *myecs.ECSResource, targets []tmuxTarget, err error) error {
+	for _, target := range targets {
+		if termErr := terminateSession(ecsClient, target.sessionID); termErr != nil {
+			log.Warn(termErr)
+		}
+	}
+	return err
+}
+
+// openTmuxTargets creates the panes or windows for targets. A new detached
+// session is created when session is not empty, otherwise windows are added
+// to the current tmux session.
+func openTmuxTargets(targets []tmuxTarget, layout string, synchronize bool, session string) error {
+	window := ""
+	for i, target := range targets {
+		var args []string
+		switch {
+		case i == 0 && session != "":
+			args = []string{"new-session", "-d", "-s", session, "-n", target.title}
+		case i == 0 || layout == tmuxWindows:
+			args = []string{"new-window", "-n", target.title}
+			if session != "" {
+				args = append(args, "-t", session+":")
+			}
+		default:
+			args = []string{"split-window", "-t", window}
+		}
+		args = append(args, "-P", "-F", "#{window_id} #{pane_id}", target.command)
+
+		out, err := tmuxCommand(args...)
+		if err != nil {
+			return err
+		}
+		ids := strings.Fields(out)
+		if len(ids) != 2 {
+			return fmt.Errorf("unexpected tmux output: %q", out)
+		}
+		window = ids[0]
+
+		if _, err := tmuxCommand("select-pane", "-t", ids[1], "-T", target.title); err != nil {
+			return err
+		}
+		if layout == tmuxPanes && i > 0 {
+			if _, err := tmuxCommand("select-layout", "-t", window, "tiled"); err != nil {
+				return err
+			}
+		}
+	}
+
+	if layout != tmuxPanes {
+		return nil
+	}
+	if _, err := tmuxCommand("set-window-option", "-t", window, "pane-border-status", "top"); err != nil {
+		return err
+	}
+	if synchronize {
+		if _, err := tmuxCommand("set-window-option", "-t", window, "synchronize-panes", "on"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
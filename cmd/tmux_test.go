@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	myecs "github.com/jedipunkz/miniecs/internal/pkg/aws/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+func recordTmux(t *testing.T) *[][]string {
+	original := tmuxCommand
+	t.Cleanup(func() { tmuxCommand = original })
+
+	var calls [][]string
+	tmuxCommand = func(args ...string) (string, error) {
+		calls = append(calls, args)
+		return fmt.Sprintf("@1 %%%d", len(calls)), nil
+	}
+	return &calls
+}
+
+func TestOpenTmuxTargetsPanes(t *testing.T) {
+	calls := recordTmux(t)
+	targets := []tmuxTarget{
+		{title: "prod/api/task1", command: "session-manager-plugin one"},
+		{title: "prod/api/task2", command: "session-manager-plugin two"},
+	}
+
+	assert.NoError(t, openTmuxTargets(targets, tmuxPanes, true, ""))
+	assert.Equal(t, [][]string{
+		{"new-window", "-n", "prod/api/task1", "-P", "-F", "#{window_id} #{pane_id}", "session-manager-plugin one"},
+		{"select-pane", "-t", "%1", "-T", "prod/api/task1"},
+		{"split-window", "-t", "@1", "-P", "-F", "#{window_id} #{pane_id}", "session-manager-plugin two"},
+		{"select-pane", "-t", "%3", "-T", "prod/api/task2"},
+		{"select-layout", "-t", "@1", "tiled"},
+		{"set-window-option", "-t", "@1", "pane-border-status", "top"},
+		{"set-window-option", "-t", "@1", "synchronize-panes", "on"},
+	}, *calls)
+}
+
+func TestOpenTmuxTargetsWindowsInNewSession(t *testing.T) {
+	calls := recordTmux(t)
+	targets := []tmuxTarget{
+		{title: "prod/api/task1", command: "one"},
+		{title: "prod/api/task2", command: "two"},
+	}
+
+	assert.NoError(t, openTmuxTargets(targets, tmuxWindows, false, "miniecs-1"))
+	assert.Equal(t, []string{"new-session", "-d", "-s", "miniecs-1", "-n", "prod/api/task1", "-P", "-F", "#{window_id} #{pane_id}", "one"}, (*calls)[0])
+	assert.Equal(t, []string{"new-window", "-n", "prod/api/task2", "-t", "miniecs-1:", "-P", "-F", "#{window_id} #{pane_id}", "two"}, (*calls)[2])
+	assert.Len(t, *calls, 4)
+}
+
+func TestEndTmuxSessions(t *testing.T) {
+	original := terminateSession
+	t.Cleanup(func() { terminateSession = original })
+	var terminated []string
+	terminateSession = func(ecsClient *myecs.ECSResource, sessionID string) error {
+		terminated = append(terminated, sessionID)
+		return errors.New("access denied")
+	}
+
+	targets := []tmuxTarget{
+		{title: "prod/api/task1", command: "one", sessionID: "s1"},
+		{title: "prod/api/task2", command: "two", sessionID: "s2"},
+	}
+	tmuxErr := errors.New("tmux new-window: exit status 1")
+	assert.Equal(t, tmuxErr, endTmuxSessions(&myecs.ECSResource{}, targets, tmuxErr))
+	assert.Equal(t, []string{"s1", "s2"}, terminated)
+}
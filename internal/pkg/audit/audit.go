@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry is one line of the audit log, describing an execute command
+// session.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Caller    string    `json:"caller,omitempty"`
+	Account   string    `json:"account,omitempty"`
+	Region    string    `json:"region"`
+	Cluster   string    `json:"cluster"`
+	Task      string    `json:"task"`
+	Container string    `json:"container"`
+	Command   string    `json:"command"`
+	SessionID string    `json:"session_id,omitempty"`
+	// Duration is in seconds.
+	Duration float64 `json:"duration"`
+	// ExitCode is the exit status of the remote command, omitted when it
+	// is not known.
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Detached bool   `json:"detached,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Log appends entries to a JSONL file. It is safe for concurrent use.
+type Log struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append writes entry as a single line at the end of the log.
+func (l *Log) Append(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// Read returns all entries of the log in the order they were written. A
+// missing log has no entries.
+func (l *Log) Read() ([]Entry, error) {
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log %s line %d: %w", l.path, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendAndRead(t *testing.T) {
+	log := NewLog(filepath.Join(t.TempDir(), "nested", "audit.jsonl"))
+
+	entries, err := log.Read()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	code := 3
+	first := Entry{
+		Time:      time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Caller:    "arn:aws:sts::123456789012:assumed-role/dev/alice",
+		Account:   "123456789012",
+		Region:    "ap-northeast-1",
+		Cluster:   "prod",
+		Task:      "arn:aws:ecs:ap-northeast-1:123456789012:task/prod/abc",
+		Container: "app",
+		Command:   "cat /srv/app/REVISION",
+		SessionID: "ecs-execute-command-123",
+		Duration:  1.5,
+		ExitCode:  &code,
+	}
+	assert.NoError(t, log.Append(first))
+	assert.NoError(t, log.Append(Entry{Cluster: "stg", Detached: true}))
+
+	entries, err = log.Read()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, first, entries[0])
+	assert.Nil(t, entries[1].ExitCode)
+	assert.True(t, entries[1].Detached)
+
+	info, err := os.Stat(log.Path())
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestAppendConcurrently(t *testing.T) {
+	log := NewLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, log.Append(Entry{Command: "uptime"}))
+		}()
+	}
+	wg.Wait()
+
+	entries, err := log.Read()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 20)
+}
+
+func TestReadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	assert.NoError(t, os.WriteFile(path, []byte("{}\nnot json\n"), 0o600))
+
+	_, err := NewLog(path).Read()
+	assert.ErrorContains(t, err, "line 2")
+}
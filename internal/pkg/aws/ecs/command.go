@@ -0,0 +1,85 @@
+package ecs
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// ShellJoin quotes argv for a POSIX shell so that every element reaches the
+// remote command as a single argument.
+func ShellJoin(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// PowerShellJoin is ShellJoin for Windows containers, invoking argv with the
+// PowerShell call operator.
+func PowerShellJoin(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		quoted[i] = powerShellQuote(arg)
+	}
+	return "& " + strings.Join(quoted, " ")
+}
+
+func powerShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// encodePowerShell encodes script for powershell.exe -EncodedCommand, which
+// expects base64 of UTF-16LE text.
+func encodePowerShell(script string) string {
+	units := utf16.Encode([]rune(script))
+	buf := make([]byte, 0, len(units)*2)
+	for _, u := range units {
+		buf = append(buf, byte(u), byte(u>>8))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if strings.IndexFunc(s, needsQuote) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func needsQuote(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("-_./=:@%+,", r)
+}
+
+// shellCandidates are probed in order of preference by DetectShell.
+var shellCandidates = []string{"bash", "zsh", "ash", "sh"}
+
+func shellProbeCommand() string {
+	return fmt.Sprintf(
+		"for s in %s; do command -v $s >/dev/null 2>&1 && { echo $s; exit 0; }; done; exit 1",
+		strings.Join(shellCandidates, " "))
+}
+
+// parseShellProbe returns the shell named in the probe output.
+func parseShellProbe(output string) (string, bool) {
+	lines := strings.Fields(output)
+	if len(lines) == 0 {
+		return "", false
+	}
+	shell := lines[len(lines)-1]
+	for _, candidate := range shellCandidates {
+		if shell == candidate {
+			return shell, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,32 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShellJoin(t *testing.T) {
+	assert.Equal(t, "ls -la /srv/app", ShellJoin([]string{"ls", "-la", "/srv/app"}))
+	assert.Equal(t, `echo 'a b' '' 'it'\''s' '$HOME'`, ShellJoin([]string{"echo", "a b", "", "it's", "$HOME"}))
+}
+
+func TestParseShellProbe(t *testing.T) {
+	shell, ok := parseShellProbe("bash\n")
+	assert.True(t, ok)
+	assert.Equal(t, "bash", shell)
+
+	_, ok = parseShellProbe("")
+	assert.False(t, ok)
+	_, ok = parseShellProbe("fish\n")
+	assert.False(t, ok)
+}
+
+func TestPowerShellJoin(t *testing.T) {
+	assert.Equal(t, `& 'Get-ChildItem' 'C:\app' 'it''s'`, PowerShellJoin([]string{"Get-ChildItem", `C:\app`, "it's"}))
+}
+
+func TestEncodePowerShell(t *testing.T) {
+	// powershell.exe expects base64 of the UTF-16LE encoded script.
+	assert.Equal(t, "ZABpAHIA", encodePowerShell("dir"))
+}
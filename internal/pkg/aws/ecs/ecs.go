@@ -1,16 +1,21 @@
 package ecs
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -32,15 +37,42 @@ type ECSClient interface {
 	DescribeTasks(ctx context.Context, params *ecs.DescribeTasksInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTasksOutput, error)
 	DescribeTaskDefinition(ctx context.Context, params *ecs.DescribeTaskDefinitionInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTaskDefinitionOutput, error)
 	ExecuteCommand(ctx context.Context, params *ecs.ExecuteCommandInput, optFns ...func(*ecs.Options)) (*ecs.ExecuteCommandOutput, error)
+	StopTask(ctx context.Context, params *ecs.StopTaskInput, optFns ...func(*ecs.Options)) (*ecs.StopTaskOutput, error)
 }
 
 type ECSResource struct {
 	client     ECSClient
+	ssmClient  SSMClient
 	execRunner ECSExecRunner
 
 	Clusters []ECSCluster
 
 	Region string
+
+	// OnSession is called after every execute command session, when set.
+	OnSession func(Session)
+	// OnSessionStart is called once an execute command session has been
+	// started, before anything runs in it, when set.
+	OnSessionStart func(Session)
+}
+
+// Session describes an execute command session reported to OnSession.
+type Session struct {
+	Cluster   string
+	Task      string
+	Container string
+	// Command is the command as requested, before any wrapping.
+	Command   string
+	SessionID string
+	Started   time.Time
+	Duration  time.Duration
+	// ExitCode is the exit status of the remote command, or -1 when it is
+	// not known, as for interactive and detached sessions.
+	ExitCode int
+	// Detached is set for sessions handed out by SessionCommand, which are
+	// reported as soon as they start.
+	Detached bool
+	Err      error
 }
 
 type ECSCluster struct {
@@ -64,6 +96,27 @@ type ECSTask struct {
 	Containers     []ECSContainer
 	LastStatus     string
 	DesiredStatus  string
+	OSFamily       string
+	// StartedAt is zero until the task has started.
+	StartedAt        time.Time
+	AvailabilityZone string
+	// HealthStatus is HEALTHY, UNHEALTHY or UNKNOWN.
+	HealthStatus string
+	PrivateIP    string
+	// LaunchType is EC2, FARGATE or EXTERNAL, empty for tasks started by a
+	// capacity provider strategy.
+	LaunchType       string
+	CapacityProvider string
+	// CPU and Memory are the task size in CPU units and MiB.
+	CPU         string
+	Memory      string
+	ExecEnabled bool
+}
+
+// IsWindows reports whether the task runs on a Windows operating system
+// family according to the runtime platform of its task definition.
+func (t ECSTask) IsWindows() bool {
+	return strings.HasPrefix(t.OSFamily, "WINDOWS")
 }
 
 type ECSContainer struct {
@@ -73,11 +126,46 @@ type ECSContainer struct {
 	Shell         string
 	Status        string
 	Image         string
+	ImageDigest   string
+	Essential     bool
+	User          string
+	WorkDir       string
+	Hidden        bool
+	Commands      map[string]string
+	// SecretNames are the environment variables the task definition fills
+	// from Secrets Manager or Parameter Store.
+	SecretNames []string
+	// CPU, Memory and MemoryReservation are the limits of the container
+	// definition in CPU units and MiB, zero when not set.
+	CPU               int32
+	Memory            int32
+	MemoryReservation int32
+	// ExecAgentStatus is the status of the execute command agent of the
+	// running container.
+	ExecAgentStatus string
+	// RuntimeID is the Docker ID of the running container, part of its
+	// Session Manager target.
+	RuntimeID string
+	// LogGroup, LogStreamPrefix and LogRegion come from the awslogs log
+	// configuration of the container, empty for other log drivers.
+	LogGroup        string
+	LogStreamPrefix string
+	LogRegion       string
+}
+
+// LogStream returns the CloudWatch Logs stream the container of task writes
+// to, empty when it does not log with awslogs and a stream prefix.
+func (c ECSContainer) LogStream(taskArn string) string {
+	if c.LogGroup == "" || c.LogStreamPrefix == "" {
+		return ""
+	}
+	return c.LogStreamPrefix + "/" + c.ContainerName + "/" + taskArn[strings.LastIndex(taskArn, "/")+1:]
 }
 
 func NewECS(cfg aws.Config, region string) *ECSResource {
 	return &ECSResource{
 		client:     ecs.NewFromConfig(cfg),
+		ssmClient:  ssm.NewFromConfig(cfg),
 		execRunner: &DefaultECSExecRunner{},
 		Clusters:   []ECSCluster{},
 		Region:     region,
@@ -93,9 +181,139 @@ func newECSForTesting(client ECSClient, region string) *ECSResource {
 	}
 }
 
+// ExecOptions controls how a one-shot command is attached to the local
+// terminal by ExecuteCommandWithExitCode.
+type ExecOptions struct {
+	// Stdin is forwarded to the remote command when set. Its end ends the
+	// input of the remote command in POSIX containers.
+	Stdin io.Reader
+	// TTY keeps echo and newline translation of the remote pty.
+	TTY bool
+	// Windows wraps the command for PowerShell instead of sh.
+	Windows bool
+	// Stdout and Stderr default to the process' own when nil.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
 func (e *ECSResource) ExecuteCommand(input ecs.ExecuteCommandInput) error {
+	session := newSession(input, aws.ToString(input.Command))
+	sessionID, err := e.startSession(input, os.Stdin, os.Stdout, os.Stderr)
+	session.SessionID, session.Err = sessionID, err
+	e.reportSession(session)
+	return err
+}
+
+// ExecuteCommandWithExitCode runs input.Command and returns a
+// *RemoteExitError when the command exits non-zero inside the container.
+func (e *ECSResource) ExecuteCommandWithExitCode(input ecs.ExecuteCommandInput, opts ExecOptions) error {
+	session := newSession(input, aws.ToString(input.Command))
+	sessionID, err := e.executeWithExitCode(input, opts)
+	session.SessionID, session.Err = sessionID, err
+
+	var remoteErr *RemoteExitError
+	switch {
+	case err == nil:
+		session.ExitCode = 0
+	case errors.As(err, &remoteErr):
+		session.ExitCode = remoteErr.Code
+	}
+	e.reportSession(session)
+	return err
+}
+
+func (e *ECSResource) executeWithExitCode(input ecs.ExecuteCommandInput, opts ExecOptions) (string, error) {
+	markers := newExitMarkers()
+	if opts.Windows {
+		input.Command = aws.String(wrapWithExitCodePowerShell(aws.ToString(input.Command), markers))
+	} else {
+		input.Command = aws.String(wrapWithExitCode(aws.ToString(input.Command), markers, opts.TTY))
+	}
+
+	if opts.Stdout == nil {
+		opts.Stdout = os.Stdout
+	}
+	if opts.Stderr == nil {
+		opts.Stderr = os.Stderr
+	}
+
+	// The remote pty of a POSIX container never sees the end of the local
+	// input otherwise, and the remote command would wait for more.
+	stdin := opts.Stdin
+	if stdin != nil && !opts.Windows {
+		stdin = newEOFReader(stdin)
+	}
+
+	stdout := newExitCodeWriter(opts.Stdout, markers)
+	sessionID, err := e.startSession(input, stdin, stdout, opts.Stderr)
+	if flushErr := stdout.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		return sessionID, err
+	}
+
+	code, ok := stdout.ExitCode()
+	if !ok {
+		return sessionID, fmt.Errorf("remote exit status was not reported, the session may have been interrupted")
+	}
+	if code != 0 {
+		return sessionID, &RemoteExitError{Code: code}
+	}
+	return sessionID, nil
+}
+
+// DetectShell runs a short probe in the container described by input and
+// returns the most capable shell it found.
+func (e *ECSResource) DetectShell(input ecs.ExecuteCommandInput) (string, error) {
+	var stdout bytes.Buffer
+	input.Command = aws.String(shellProbeCommand())
+
+	err := e.ExecuteCommandWithExitCode(input, ExecOptions{Stdout: &stdout, Stderr: io.Discard})
+	if err != nil {
+		return "", fmt.Errorf("failed to detect shell: %w", err)
+	}
+
+	shell, ok := parseShellProbe(stdout.String())
+	if !ok {
+		return "", fmt.Errorf("failed to detect shell from output %q", stdout.String())
+	}
+	return shell, nil
+}
+
+func (e *ECSResource) startSession(input ecs.ExecuteCommandInput, stdin io.Reader, stdout, stderr io.Writer) (string, error) {
+	cmd, sessionID, err := e.sessionCommand(input)
+	if err != nil {
+		return sessionID, err
+	}
+
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := e.execRunner.RunCommand(cmd); err != nil {
+		return sessionID, classifyExecuteCommandError(input, err)
+	}
+	return sessionID, nil
+}
+
+// SessionCommand starts an execute command session for input and returns the
+// session-manager-plugin invocation that attaches to it, without running it,
+// and the ID of the session, to terminate it if it is never attached to.
+func (e *ECSResource) SessionCommand(input ecs.ExecuteCommandInput) (*exec.Cmd, string, error) {
+	session := newSession(input, aws.ToString(input.Command))
+	cmd, sessionID, err := e.sessionCommand(input)
+	session.SessionID, session.Err, session.Detached = sessionID, err, true
+	e.reportSession(session)
+	return cmd, sessionID, err
+}
+
+func (e *ECSResource) sessionCommand(input ecs.ExecuteCommandInput) (*exec.Cmd, string, error) {
 	if e.client == nil {
-		return fmt.Errorf("ECS client is not initialized")
+		return nil, "", fmt.Errorf("ECS client is not initialized")
+	}
+
+	if _, err := exec.LookPath("session-manager-plugin"); err != nil {
+		return nil, "", classifyExecuteCommandError(input, err)
 	}
 
 	ctx := context.TODO()
@@ -103,22 +321,52 @@ func (e *ECSResource) ExecuteCommand(input ecs.ExecuteCommandInput) error {
 
 	execCommandOutput, err := e.client.ExecuteCommand(ctx, &preparedInput)
 	if err != nil {
-		return fmt.Errorf("failed to execute command: %w", err)
+		return nil, "", classifyExecuteCommandError(input, err)
+	}
+
+	var sessionID string
+	if execCommandOutput.Session != nil {
+		sessionID = aws.ToString(execCommandOutput.Session.SessionId)
+	}
+	if e.OnSessionStart != nil {
+		session := newSession(input, aws.ToString(input.Command))
+		session.SessionID = sessionID
+		e.OnSessionStart(session)
 	}
 
 	sessionInfo, err := json.Marshal(execCommandOutput.Session)
 	if err != nil {
-		return fmt.Errorf("failed to marshal session info: %w", err)
+		return nil, sessionID, fmt.Errorf("failed to marshal session info: %w", err)
 	}
 
 	target := fmt.Sprintf("ecs:%s_%s_%s", *input.Cluster, *input.Task, *input.Container)
 	targetJSON, err := e.buildSSMTargetJSON(target)
 	if err != nil {
-		return fmt.Errorf("failed to create target JSON: %w", err)
+		return nil, sessionID, fmt.Errorf("failed to create target JSON: %w", err)
+	}
+
+	return e.buildSessionManagerCommand(sessionInfo, targetJSON), sessionID, nil
+}
+
+func newSession(input ecs.ExecuteCommandInput, command string) Session {
+	return Session{
+		Cluster:   aws.ToString(input.Cluster),
+		Task:      aws.ToString(input.Task),
+		Container: aws.ToString(input.Container),
+		Command:   command,
+		Started:   time.Now(),
+		ExitCode:  -1,
 	}
+}
 
-	cmd := e.buildSessionManagerCommand(sessionInfo, targetJSON)
-	return e.execRunner.RunCommand(cmd)
+func (e *ECSResource) reportSession(session Session) {
+	if e.OnSession == nil {
+		return
+	}
+	if !session.Detached {
+		session.Duration = time.Since(session.Started)
+	}
+	e.OnSession(session)
 }
 
 func (e *ECSResource) buildExecuteCommandInput(input ecs.ExecuteCommandInput) ecs.ExecuteCommandInput {
@@ -280,7 +528,7 @@ func (e *ECSResource) describeTask(ctx context.Context, cluster, taskArn string)
 	}
 
 	if len(describeTasksOutput.Tasks) == 0 {
-		return nil, fmt.Errorf("task not found: %s", taskArn)
+		return nil, &Error{Kind: ErrTaskGone, Cluster: cluster, Task: taskArn}
 	}
 
 	taskDefinitionArn := describeTasksOutput.Tasks[0].TaskDefinitionArn
@@ -299,16 +547,116 @@ func (e *ECSResource) describeTask(ctx context.Context, cluster, taskArn string)
 	}
 
 	return &ECSTask{
-		TaskArn:        taskArn,
-		TaskDefinition: *taskDefinitionArn,
-		ClusterName:    cluster,
-		LastStatus:     lastStatus,
-		DesiredStatus:  desiredStatus,
-		Containers:     []ECSContainer{},
+		TaskArn:          taskArn,
+		TaskDefinition:   *taskDefinitionArn,
+		ClusterName:      cluster,
+		LastStatus:       lastStatus,
+		DesiredStatus:    desiredStatus,
+		StartedAt:        aws.ToTime(task.StartedAt),
+		AvailabilityZone: aws.ToString(task.AvailabilityZone),
+		HealthStatus:     string(task.HealthStatus),
+		PrivateIP:        parsePrivateIP(task),
+		LaunchType:       string(task.LaunchType),
+		CapacityProvider: aws.ToString(task.CapacityProviderName),
+		CPU:              aws.ToString(task.Cpu),
+		Memory:           aws.ToString(task.Memory),
+		ExecEnabled:      task.EnableExecuteCommand,
+		Containers:       parseRuntimeContainers(task.Containers, taskArn),
 	}, nil
 }
 
+// parsePrivateIP returns the private IPv4 address of the first network
+// interface of task, as reported for its containers or its ENI attachment.
+func parsePrivateIP(task types.Task) string {
+	for _, container := range task.Containers {
+		for _, networkInterface := range container.NetworkInterfaces {
+			if ip := aws.ToString(networkInterface.PrivateIpv4Address); ip != "" {
+				return ip
+			}
+		}
+	}
+	for _, attachment := range task.Attachments {
+		for _, detail := range attachment.Details {
+			if aws.ToString(detail.Name) == "privateIPv4Address" {
+				return aws.ToString(detail.Value)
+			}
+		}
+	}
+	return ""
+}
+
+func parseRuntimeContainers(runtimeContainers []types.Container, taskArn string) []ECSContainer {
+	containers := []ECSContainer{}
+	for _, container := range runtimeContainers {
+		runtimeContainer := ECSContainer{
+			ContainerName: aws.ToString(container.Name),
+			ContainerArn:  aws.ToString(container.ContainerArn),
+			TaskArn:       taskArn,
+			Status:        aws.ToString(container.LastStatus),
+			Image:         aws.ToString(container.Image),
+			ImageDigest:   aws.ToString(container.ImageDigest),
+			RuntimeID:     aws.ToString(container.RuntimeId),
+		}
+		for _, agent := range container.ManagedAgents {
+			if agent.Name == types.ManagedAgentNameExecuteCommandAgent {
+				runtimeContainer.ExecAgentStatus = aws.ToString(agent.LastStatus)
+			}
+		}
+		containers = append(containers, runtimeContainer)
+	}
+	return containers
+}
+
+// mergeRuntimeContainers completes the containers of a task definition with
+// the state reported for the running task.
+func mergeRuntimeContainers(definitions, runtime []ECSContainer) []ECSContainer {
+	byName := make(map[string]ECSContainer, len(runtime))
+	for _, container := range runtime {
+		byName[container.ContainerName] = container
+	}
+
+	merged := make([]ECSContainer, len(definitions))
+	for i, container := range definitions {
+		if running, ok := byName[container.ContainerName]; ok {
+			container.ContainerArn = running.ContainerArn
+			container.TaskArn = running.TaskArn
+			container.Status = running.Status
+			container.ImageDigest = running.ImageDigest
+			container.ExecAgentStatus = running.ExecAgentStatus
+			container.RuntimeID = running.RuntimeID
+		}
+		merged[i] = container
+	}
+	return merged
+}
+
+// StopTask stops a task, which the service scheduler then replaces.
+func (e *ECSResource) StopTask(ctx context.Context, cluster, taskArn, reason string) error {
+	_, err := e.client.StopTask(ctx, &ecs.StopTaskInput{
+		Cluster: aws.String(cluster),
+		Task:    aws.String(taskArn),
+		Reason:  aws.String(reason),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to stop task %s: %w", taskArn, err)
+	}
+	return nil
+}
+
 func (e *ECSResource) ListContainersForTask(ctx context.Context, taskDefinition string) ([]ECSContainer, error) {
+	result, err := e.describeTaskDefinition(ctx, taskDefinition)
+	if err != nil {
+		return nil, err
+	}
+
+	containers, err := e.parseContainerDefinitions(result.ContainerDefinitions, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create containers: %w", err)
+	}
+	return containers, nil
+}
+
+func (e *ECSResource) describeTaskDefinition(ctx context.Context, taskDefinition string) (*types.TaskDefinition, error) {
 	input := &ecs.DescribeTaskDefinitionInput{
 		TaskDefinition: aws.String(taskDefinition),
 	}
@@ -317,12 +665,17 @@ func (e *ECSResource) ListContainersForTask(ctx context.Context, taskDefinition
 	if err != nil {
 		return nil, fmt.Errorf("failed to describe task definition: %w", err)
 	}
+	if result.TaskDefinition == nil {
+		return nil, fmt.Errorf("task definition not found: %s", taskDefinition)
+	}
+	return result.TaskDefinition, nil
+}
 
-	containers, err := e.parseContainerDefinitions(result.TaskDefinition.ContainerDefinitions, "")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create containers: %w", err)
+func parseOSFamily(taskDefinition *types.TaskDefinition) string {
+	if taskDefinition.RuntimePlatform == nil {
+		return ""
 	}
-	return containers, nil
+	return string(taskDefinition.RuntimePlatform.OperatingSystemFamily)
 }
 
 func (e *ECSResource) parseContainerDefinitions(containerDefinitions []types.ContainerDefinition, taskArn string) ([]ECSContainer, error) {
@@ -335,12 +688,26 @@ func (e *ECSResource) parseContainerDefinitions(containerDefinitions []types.Con
 		if container.Image != nil {
 			image = *container.Image
 		}
-		containers = append(containers, ECSContainer{
-			ContainerName: *container.Name,
-			TaskArn:       taskArn,
-			Image:         image,
-			Status:        "",
-		})
+		ecsContainer := ECSContainer{
+			ContainerName:     *container.Name,
+			TaskArn:           taskArn,
+			Image:             image,
+			Status:            "",
+			Essential:         container.Essential == nil || *container.Essential,
+			CPU:               container.Cpu,
+			Memory:            aws.ToInt32(container.Memory),
+			MemoryReservation: aws.ToInt32(container.MemoryReservation),
+		}
+		for _, secret := range container.Secrets {
+			ecsContainer.SecretNames = append(ecsContainer.SecretNames, aws.ToString(secret.Name))
+		}
+		if logs := container.LogConfiguration; logs != nil && logs.LogDriver == types.LogDriverAwslogs {
+			ecsContainer.LogGroup = logs.Options["awslogs-group"]
+			ecsContainer.LogStreamPrefix = logs.Options["awslogs-stream-prefix"]
+			ecsContainer.LogRegion = logs.Options["awslogs-region"]
+		}
+		applyLabels(&ecsContainer, container.DockerLabels)
+		containers = append(containers, ecsContainer)
 	}
 	return containers, nil
 }
@@ -427,14 +794,20 @@ func (e *ECSResource) GetServiceResources(ctx context.Context, cluster ECSCluste
 
 	// For each task, get containers and create a complete resource
 	for _, task := range tasks {
-		containers, err := e.ListContainersForTask(ctx, task.TaskDefinition)
+		taskDefinition, err := e.describeTaskDefinition(ctx, task.TaskDefinition)
+		if err != nil {
+			log.Printf("Failed to list containers for task %s: %v", task.TaskArn, err)
+			continue
+		}
+		containers, err := e.parseContainerDefinitions(taskDefinition.ContainerDefinitions, "")
 		if err != nil {
 			log.Printf("Failed to list containers for task %s: %v", task.TaskArn, err)
 			continue
 		}
 
 		// Update task with containers
-		task.Containers = containers
+		task.Containers = mergeRuntimeContainers(containers, task.Containers)
+		task.OSFamily = parseOSFamily(taskDefinition)
 
 		// Create a complete resource with all hierarchical data
 		resource := ECSResource{
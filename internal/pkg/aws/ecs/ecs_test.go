@@ -2,7 +2,12 @@ package ecs
 
 import (
 	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
@@ -40,6 +45,11 @@ func (m *MockECSClient) DescribeTaskDefinition(ctx context.Context, params *ecs.
 	return args.Get(0).(*ecs.DescribeTaskDefinitionOutput), args.Error(1)
 }
 
+func (m *MockECSClient) StopTask(ctx context.Context, params *ecs.StopTaskInput, optFns ...func(*ecs.Options)) (*ecs.StopTaskOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*ecs.StopTaskOutput), args.Error(1)
+}
+
 func (m *MockECSClient) ExecuteCommand(ctx context.Context, params *ecs.ExecuteCommandInput, optFns ...func(*ecs.Options)) (*ecs.ExecuteCommandOutput, error) {
 	args := m.Called(ctx, params)
 	return args.Get(0).(*ecs.ExecuteCommandOutput), args.Error(1)
@@ -124,6 +134,54 @@ func TestGetTasks(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestDescribeTask(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ecsResource := newECSForTesting(mockClient, "ap-northeast-1")
+
+	taskArn := "arn:aws:ecs:ap-northeast-1:123456789012:task/test-cluster/task-id"
+	startedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mockClient.On("DescribeTasks", mock.Anything, mock.Anything).Return(&ecs.DescribeTasksOutput{
+		Tasks: []types.Task{{
+			TaskArn:              aws.String(taskArn),
+			TaskDefinitionArn:    aws.String("arn:aws:ecs:ap-northeast-1:123456789012:task-definition/test-task:1"),
+			LastStatus:           aws.String("RUNNING"),
+			StartedAt:            aws.Time(startedAt),
+			AvailabilityZone:     aws.String("ap-northeast-1a"),
+			HealthStatus:         types.HealthStatusHealthy,
+			LaunchType:           types.LaunchTypeFargate,
+			Cpu:                  aws.String("512"),
+			Memory:               aws.String("1024"),
+			EnableExecuteCommand: true,
+			Attachments: []types.Attachment{{
+				Type: aws.String("ElasticNetworkInterface"),
+				Details: []types.KeyValuePair{
+					{Name: aws.String("subnetId"), Value: aws.String("subnet-0123")},
+					{Name: aws.String("privateIPv4Address"), Value: aws.String("10.0.1.23")},
+				},
+			}},
+			Containers: []types.Container{{
+				Name: aws.String("app"),
+				ManagedAgents: []types.ManagedAgent{{
+					Name:       types.ManagedAgentNameExecuteCommandAgent,
+					LastStatus: aws.String("RUNNING"),
+				}},
+			}},
+		}},
+	}, nil)
+
+	task, err := ecsResource.describeTask(context.Background(), "test-cluster", taskArn)
+	assert.NoError(t, err)
+	assert.Equal(t, startedAt, task.StartedAt)
+	assert.Equal(t, "ap-northeast-1a", task.AvailabilityZone)
+	assert.Equal(t, "HEALTHY", task.HealthStatus)
+	assert.Equal(t, "10.0.1.23", task.PrivateIP)
+	assert.Equal(t, "FARGATE", task.LaunchType)
+	assert.Equal(t, "512", task.CPU)
+	assert.Equal(t, "1024", task.Memory)
+	assert.True(t, task.ExecEnabled)
+	assert.Equal(t, "RUNNING", task.Containers[0].ExecAgentStatus)
+}
+
 func TestListContainersForTask(t *testing.T) {
 	mockClient := new(MockECSClient)
 	ecsResource := newECSForTesting(mockClient, "ap-northeast-1")
@@ -147,4 +205,201 @@ func TestListContainersForTask(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, containers, 1)
 	assert.Equal(t, containerName, containers[0].ContainerName)
-}
\ No newline at end of file
+}
+
+func TestListContainersForTaskLogConfiguration(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ecsResource := newECSForTesting(mockClient, "ap-northeast-1")
+
+	taskDefinition := "test-task:1"
+	containerName := "test-container"
+
+	mockClient.On("DescribeTaskDefinition", mock.Anything, &ecs.DescribeTaskDefinitionInput{
+		TaskDefinition: aws.String(taskDefinition),
+	}).Return(&ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{
+			ContainerDefinitions: []types.ContainerDefinition{
+				{
+					Name: aws.String(containerName),
+					LogConfiguration: &types.LogConfiguration{
+						LogDriver: types.LogDriverAwslogs,
+						Options: map[string]string{
+							"awslogs-group":         "/ecs/test",
+							"awslogs-region":        "ap-northeast-1",
+							"awslogs-stream-prefix": "ecs",
+						},
+					},
+				},
+			},
+		},
+	}, nil)
+
+	containers, err := ecsResource.ListContainersForTask(context.Background(), taskDefinition)
+	assert.NoError(t, err)
+	assert.Len(t, containers, 1)
+	assert.Equal(t, "/ecs/test", containers[0].LogGroup)
+	assert.Equal(t, "ap-northeast-1", containers[0].LogRegion)
+	assert.Equal(t, "ecs/test-container/task-id", containers[0].LogStream("arn:aws:ecs:ap-northeast-1:123456789012:task/test-cluster/task-id"))
+}
+
+func TestStopTask(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ecsResource := newECSForTesting(mockClient, "ap-northeast-1")
+
+	taskArn := "arn:aws:ecs:ap-northeast-1:123456789012:task/test-cluster/task-id"
+	mockClient.On("StopTask", mock.Anything, &ecs.StopTaskInput{
+		Cluster: aws.String("test-cluster"),
+		Task:    aws.String(taskArn),
+		Reason:  aws.String("stopped with miniecs"),
+	}).Return(&ecs.StopTaskOutput{}, nil)
+
+	assert.NoError(t, ecsResource.StopTask(context.Background(), "test-cluster", taskArn, "stopped with miniecs"))
+	mockClient.AssertExpectations(t)
+}
+
+func TestParseOSFamily(t *testing.T) {
+	windows := &types.TaskDefinition{
+		RuntimePlatform: &types.RuntimePlatform{
+			OperatingSystemFamily: types.OSFamilyWindowsServer2022Core,
+		},
+	}
+
+	assert.Equal(t, "WINDOWS_SERVER_2022_CORE", parseOSFamily(windows))
+	assert.True(t, ECSTask{OSFamily: parseOSFamily(windows)}.IsWindows())
+	assert.Empty(t, parseOSFamily(&types.TaskDefinition{}))
+	assert.False(t, ECSTask{}.IsWindows())
+}
+
+func TestMergeRuntimeContainers(t *testing.T) {
+	definitions := []ECSContainer{
+		{ContainerName: "app", Image: "app:latest"},
+		{ContainerName: "envoy", Image: "envoy:v1"},
+	}
+	runtime := []ECSContainer{
+		{ContainerName: "app", TaskArn: "task", Status: "RUNNING", ImageDigest: "sha256:abc", RuntimeID: "task-123"},
+	}
+
+	merged := mergeRuntimeContainers(definitions, runtime)
+	assert.Len(t, merged, 2)
+	assert.Equal(t, "app:latest", merged[0].Image)
+	assert.Equal(t, "sha256:abc", merged[0].ImageDigest)
+	assert.Equal(t, "RUNNING", merged[0].Status)
+	assert.Equal(t, "task-123", merged[0].RuntimeID)
+	assert.Empty(t, merged[1].ImageDigest)
+}
+
+type fakeExecRunner struct {
+	err error
+}
+
+func (r *fakeExecRunner) RunCommand(cmd *exec.Cmd) error {
+	return r.err
+}
+
+func TestOnSession(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "session-manager-plugin"), []byte("#!/bin/sh\n"), 0o755))
+	t.Setenv("PATH", dir)
+
+	mockClient := new(MockECSClient)
+	mockClient.On("ExecuteCommand", mock.Anything, mock.Anything).Return(&ecs.ExecuteCommandOutput{
+		Session: &types.Session{SessionId: aws.String("ecs-execute-command-1")},
+	}, nil)
+
+	ecsResource := newECSForTesting(mockClient, "ap-northeast-1")
+	ecsResource.execRunner = &fakeExecRunner{}
+	var sessions []Session
+	ecsResource.OnSession = func(s Session) {
+		sessions = append(sessions, s)
+	}
+
+	input := ecs.ExecuteCommandInput{
+		Cluster:   aws.String("prod"),
+		Task:      aws.String("task1"),
+		Container: aws.String("app"),
+		Command:   aws.String("bash"),
+	}
+	assert.NoError(t, ecsResource.ExecuteCommand(input))
+	_, sessionID, err := ecsResource.SessionCommand(input)
+	assert.NoError(t, err)
+	assert.Equal(t, "ecs-execute-command-1", sessionID)
+
+	// the fake runner prints no exit marker
+	err = ecsResource.ExecuteCommandWithExitCode(input, ExecOptions{Stdout: io.Discard, Stderr: io.Discard})
+	assert.Error(t, err)
+
+	assert.Len(t, sessions, 3)
+	assert.Equal(t, "prod", sessions[0].Cluster)
+	assert.Equal(t, "bash", sessions[0].Command)
+	assert.Equal(t, "ecs-execute-command-1", sessions[0].SessionID)
+	assert.Equal(t, -1, sessions[0].ExitCode)
+	assert.False(t, sessions[0].Detached)
+	assert.True(t, sessions[1].Detached)
+	assert.Zero(t, sessions[1].Duration)
+	assert.Equal(t, "bash", sessions[2].Command)
+	assert.Error(t, sessions[2].Err)
+
+	t.Setenv("PATH", t.TempDir())
+	assert.ErrorIs(t, ecsResource.ExecuteCommand(input), ErrPluginMissing)
+	assert.ErrorIs(t, sessions[3].Err, ErrPluginMissing)
+	assert.Empty(t, sessions[3].SessionID)
+}
+
+// startCheckRunner remembers whether the session had been reported as
+// started when it ran.
+type startCheckRunner struct {
+	started      *bool
+	startedOnRun bool
+}
+
+func (r *startCheckRunner) RunCommand(cmd *exec.Cmd) error {
+	r.startedOnRun = *r.started
+	return nil
+}
+
+func TestOnSessionStart(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "session-manager-plugin"), []byte("#!/bin/sh\n"), 0o755))
+	t.Setenv("PATH", dir)
+
+	mockClient := new(MockECSClient)
+	mockClient.On("ExecuteCommand", mock.Anything, mock.Anything).Return(&ecs.ExecuteCommandOutput{
+		Session: &types.Session{SessionId: aws.String("ecs-execute-command-1")},
+	}, nil)
+
+	ecsResource := newECSForTesting(mockClient, "ap-northeast-1")
+	started := false
+	runner := &startCheckRunner{started: &started}
+	ecsResource.execRunner = runner
+	ecsResource.OnSessionStart = func(s Session) {
+		started = true
+		assert.Equal(t, "ecs-execute-command-1", s.SessionID)
+		assert.Equal(t, "app", s.Container)
+	}
+
+	assert.NoError(t, ecsResource.ExecuteCommand(ecs.ExecuteCommandInput{
+		Cluster:   aws.String("prod"),
+		Task:      aws.String("task1"),
+		Container: aws.String("app"),
+		Command:   aws.String("bash"),
+	}))
+	assert.True(t, runner.startedOnRun)
+}
+
+type testRecorder struct {
+	output []byte
+}
+
+func (r *testRecorder) Output(p []byte)          { r.output = append(r.output, p...) }
+func (r *testRecorder) Input(p []byte)           {}
+func (r *testRecorder) Resize(width, height int) {}
+
+func TestRunInPTY(t *testing.T) {
+	rec := &testRecorder{}
+	err := runInPTY(exec.Command("sh", "-c", "printf recorded"), rec)
+	assert.NoError(t, err)
+	assert.Contains(t, string(rec.output), "recorded")
+
+	err = runInPTY(exec.Command("sh", "-c", "exit 3"), rec)
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package ecs
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/smithy-go"
+)
+
+var (
+	ErrNoResources    = errors.New("no ECS resources found")
+	ErrExecNotEnabled = errors.New("execute command is not enabled")
+	ErrPluginMissing  = errors.New("session-manager-plugin is not installed")
+	ErrAccessDenied   = errors.New("access denied")
+	ErrTaskGone       = errors.New("task is no longer running")
+)
+
+// Error carries the target an ECS operation failed against, so that callers
+// can print a remediation hint for the failure class in Kind.
+type Error struct {
+	Kind      error
+	Cluster   string
+	Service   string
+	Task      string
+	Container string
+	Err       error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Kind.Error()
+	}
+	return fmt.Sprintf("%s: %v", e.Kind, e.Err)
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// Hint returns a human readable remediation for the failure class of err, or
+// "" when err does not match any of the sentinel errors above.
+func Hint(err error) string {
+	var target Error
+	var ecsErr *Error
+	if errors.As(err, &ecsErr) {
+		target = *ecsErr
+	}
+	service := target.Service
+	if service == "" {
+		service = "<service>"
+	}
+	cluster := target.Cluster
+	if cluster == "" {
+		cluster = "<cluster>"
+	}
+
+	switch {
+	case errors.Is(err, ErrNoResources):
+		return "check --region and that the cluster has running tasks"
+	case errors.Is(err, ErrExecNotEnabled):
+		return fmt.Sprintf(
+			"enable execute command on service %s with `aws ecs update-service --cluster %s --service %s --enable-execute-command --force-new-deployment`",
+			service, cluster, service)
+	case errors.Is(err, ErrPluginMissing):
+		return "install session-manager-plugin: https://docs.aws.amazon.com/systems-manager/latest/userguide/session-manager-working-with-install-plugin.html"
+	case errors.Is(err, ErrAccessDenied):
+		return "grant ecs:ExecuteCommand to your IAM principal and ssmmessages:* to the task role"
+	case errors.Is(err, ErrTaskGone):
+		return fmt.Sprintf("the task was stopped or replaced, run the command again to pick a running task of %s", service)
+	}
+	return ""
+}
+
+func classifyExecuteCommandError(input ecs.ExecuteCommandInput, err error) error {
+	kind := classifyError(err)
+	if kind == nil {
+		return fmt.Errorf("failed to execute command: %w", err)
+	}
+	return &Error{
+		Kind:      kind,
+		Cluster:   aws.ToString(input.Cluster),
+		Task:      aws.ToString(input.Task),
+		Container: aws.ToString(input.Container),
+		Err:       err,
+	}
+}
+
+func classifyError(err error) error {
+	if errors.Is(err, exec.ErrNotFound) {
+		return ErrPluginMissing
+	}
+
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return nil
+	}
+
+	message := strings.ToLower(apiErr.ErrorMessage())
+	switch apiErr.ErrorCode() {
+	case "AccessDeniedException":
+		return ErrAccessDenied
+	case "TargetNotConnectedException":
+		return ErrExecNotEnabled
+	case "InvalidParameterException":
+		switch {
+		case strings.Contains(message, "execute command was not enabled"):
+			return ErrExecNotEnabled
+		case strings.Contains(message, "stopped"), strings.Contains(message, "not found"):
+			return ErrTaskGone
+		}
+	}
+	return nil
+}
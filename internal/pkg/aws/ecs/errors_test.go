@@ -0,0 +1,81 @@
+package ecs
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyExecuteCommandError(t *testing.T) {
+	input := ecs.ExecuteCommandInput{
+		Cluster:   aws.String("test-cluster"),
+		Task:      aws.String("task-id"),
+		Container: aws.String("app"),
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{
+			name:     "plugin missing",
+			err:      &exec.Error{Name: "session-manager-plugin", Err: exec.ErrNotFound},
+			expected: ErrPluginMissing,
+		},
+		{
+			name:     "access denied",
+			err:      &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized"},
+			expected: ErrAccessDenied,
+		},
+		{
+			name: "execute command not enabled",
+			err: &smithy.GenericAPIError{
+				Code:    "InvalidParameterException",
+				Message: "The execute command failed because execute command was not enabled when the task was run",
+			},
+			expected: ErrExecNotEnabled,
+		},
+		{
+			name:     "task stopped",
+			err:      &smithy.GenericAPIError{Code: "InvalidParameterException", Message: "The specified task is stopped."},
+			expected: ErrTaskGone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyExecuteCommandError(input, tt.err)
+			assert.ErrorIs(t, err, tt.expected)
+			assert.ErrorIs(t, err, tt.err)
+
+			var ecsErr *Error
+			assert.True(t, errors.As(err, &ecsErr))
+			assert.Equal(t, "test-cluster", ecsErr.Cluster)
+			assert.NotEmpty(t, Hint(err))
+		})
+	}
+
+	t.Run("unknown error", func(t *testing.T) {
+		err := classifyExecuteCommandError(input, assert.AnError)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Empty(t, Hint(err))
+	})
+}
+
+func TestHint(t *testing.T) {
+	err := fmt.Errorf("login failed: %w", &Error{
+		Kind:    ErrExecNotEnabled,
+		Cluster: "prod",
+		Service: "api",
+	})
+
+	assert.Contains(t, Hint(err), "--cluster prod --service api --enable-execute-command")
+	assert.NotEmpty(t, Hint(ErrNoResources))
+}
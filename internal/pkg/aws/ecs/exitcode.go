@@ -0,0 +1,207 @@
+package ecs
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const exitMarkerPrefix = "__MINIECS_"
+
+// RemoteExitError reports that the command run inside the container finished
+// with a non-zero exit status.
+type RemoteExitError struct {
+	Code int
+}
+
+func (e *RemoteExitError) Error() string {
+	return fmt.Sprintf("remote command exited with status %d", e.Code)
+}
+
+// exitMarkers delimit the output of the wrapped command, so that the session
+// banners printed by session-manager-plugin can be dropped and the exit
+// status picked up after the command finishes.
+type exitMarkers struct {
+	start string
+	exit  string
+}
+
+func newExitMarkers() exitMarkers {
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		copy(nonce, "miniecs!")
+	}
+	prefix := exitMarkerPrefix + hex.EncodeToString(nonce)
+	return exitMarkers{
+		start: prefix + "_START_",
+		exit:  prefix + "_EXIT_",
+	}
+}
+
+// wrapWithExitCode makes the remote side print the start marker before
+// command runs and the exit marker followed by its status once it finishes.
+// Without tty the remote pty stops echoing input and translating newlines.
+func wrapWithExitCode(command string, markers exitMarkers, tty bool) string {
+	setup := ""
+	if !tty {
+		setup = "stty -echo -onlcr 2>/dev/null; "
+	}
+	script := fmt.Sprintf("%sprintf '%s'; %s; printf '%s%%d\\n' $?",
+		setup, markers.start, command, markers.exit)
+	return "sh -c " + shellQuote(script)
+}
+
+// wrapWithExitCodePowerShell is wrapWithExitCode for Windows containers. The
+// script is passed base64 encoded so that command needs no further quoting.
+// $LASTEXITCODE is only set by native programs, so cmdlets report 0 or 1
+// depending on whether they succeeded.
+func wrapWithExitCodePowerShell(command string, markers exitMarkers) string {
+	script := fmt.Sprintf(
+		"Write-Host -NoNewline '%s'; %s; $ok = $?; "+
+			"$code = if ($null -ne $LASTEXITCODE) { $LASTEXITCODE } elseif ($ok) { 0 } else { 1 }; "+
+			"Write-Host ('%s' + $code)",
+		markers.start, command, markers.exit)
+	return "powershell.exe -NoProfile -NonInteractive -EncodedCommand " + encodePowerShell(script)
+}
+
+// exitCodeWriter passes the output between the start and exit markers
+// through to w and remembers the status that followed the exit marker.
+type exitCodeWriter struct {
+	w       io.Writer
+	markers exitMarkers
+	buf     []byte
+	started bool
+	code    int
+	found   bool
+}
+
+func newExitCodeWriter(w io.Writer, markers exitMarkers) *exitCodeWriter {
+	return &exitCodeWriter{w: w, markers: markers}
+}
+
+func (w *exitCodeWriter) Write(p []byte) (int, error) {
+	if w.found {
+		return len(p), nil
+	}
+	w.buf = append(w.buf, p...)
+
+	if !w.started {
+		start := []byte(w.markers.start)
+		idx := bytes.Index(w.buf, start)
+		if idx < 0 {
+			w.drop(len(w.buf) - partialPrefixLen(w.buf, start))
+			return len(p), nil
+		}
+		w.started = true
+		w.drop(idx + len(start))
+	}
+
+	exit := []byte(w.markers.exit)
+	idx := bytes.Index(w.buf, exit)
+	if idx < 0 {
+		return len(p), w.emit(len(w.buf) - partialPrefixLen(w.buf, exit))
+	}
+	end := bytes.IndexByte(w.buf[idx:], '\n')
+	if end < 0 {
+		return len(p), w.emit(idx)
+	}
+
+	status := strings.TrimSpace(string(w.buf[idx+len(exit) : idx+end]))
+	if code, err := strconv.Atoi(status); err == nil {
+		w.code, w.found = code, true
+	}
+	if err := w.emit(idx); err != nil {
+		return 0, err
+	}
+	w.buf = nil
+	return len(p), nil
+}
+
+// emit writes the first n buffered bytes and keeps the rest.
+func (w *exitCodeWriter) emit(n int) error {
+	if n > 0 {
+		if _, err := w.w.Write(w.buf[:n]); err != nil {
+			return err
+		}
+	}
+	w.drop(n)
+	return nil
+}
+
+func (w *exitCodeWriter) drop(n int) {
+	w.buf = append([]byte(nil), w.buf[n:]...)
+}
+
+func (w *exitCodeWriter) Flush() error {
+	if !w.started {
+		return nil
+	}
+	return w.emit(len(w.buf))
+}
+
+// ExitCode returns the remote exit status and whether it was reported.
+func (w *exitCodeWriter) ExitCode() (int, bool) {
+	return w.code, w.found
+}
+
+// partialPrefixLen returns the length of the longest suffix of b that is a
+// prefix of marker, i.e. bytes that may become a marker with the next write.
+func partialPrefixLen(b, marker []byte) int {
+	limit := len(marker) - 1
+	if len(b) < limit {
+		limit = len(b)
+	}
+	for n := limit; n > 0; n-- {
+		if bytes.HasSuffix(b, marker[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// veof is the character a pty takes as the end of its input at the start
+// of a line, and as the end of an unfinished line elsewhere.
+const veof = 0x04
+
+// eofReader passes r on and then ends the input of the remote command.
+// Closing stdin of session-manager-plugin does not reach the remote pty, so
+// its input is ended with VEOF, preceded by another one that sends an
+// unfinished last line on.
+type eofReader struct {
+	r    io.Reader
+	last byte
+	tail []byte
+}
+
+func newEOFReader(r io.Reader) *eofReader {
+	return &eofReader{r: r, last: '\n'}
+}
+
+func (r *eofReader) Read(p []byte) (int, error) {
+	if r.tail == nil {
+		n, err := r.r.Read(p)
+		if n > 0 {
+			r.last = p[n-1]
+		}
+		if err != io.EOF {
+			return n, err
+		}
+		r.tail = []byte{veof}
+		if r.last != '\n' && r.last != '\r' {
+			r.tail = []byte{veof, veof}
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+	if len(r.tail) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.tail)
+	r.tail = r.tail[n:]
+	return n, nil
+}
@@ -0,0 +1,174 @@
+package ecs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExitCodeWriter(t *testing.T) {
+	markers := exitMarkers{start: "__S_", exit: "__MINIECS_EXIT_"}
+
+	tests := []struct {
+		name     string
+		chunks   []string
+		output   string
+		code     int
+		reported bool
+	}{
+		{
+			name:     "session banners are dropped",
+			chunks:   []string{"Starting session\r\n__S_hello\r\n", "__MINIECS_EXIT_0\r\n", "Exiting session\r\n"},
+			output:   "hello\r\n",
+			code:     0,
+			reported: true,
+		},
+		{
+			name:     "markers split across writes",
+			chunks:   []string{"__", "S_no newline", "__MINIECS_", "EXIT_4", "2\n"},
+			output:   "no newline",
+			code:     42,
+			reported: true,
+		},
+		{
+			name:     "partial prefix that is not a marker",
+			chunks:   []string{"__S_", "__MINI", "MAL\n"},
+			output:   "__MINIMAL\n",
+			reported: false,
+		},
+		{
+			name:     "command never started",
+			chunks:   []string{"sh: not found\r\n"},
+			output:   "",
+			reported: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			w := newExitCodeWriter(&out, markers)
+			for _, chunk := range tt.chunks {
+				n, err := w.Write([]byte(chunk))
+				assert.NoError(t, err)
+				assert.Equal(t, len(chunk), n)
+			}
+			assert.NoError(t, w.Flush())
+
+			code, reported := w.ExitCode()
+			assert.Equal(t, tt.output, out.String())
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.reported, reported)
+		})
+	}
+}
+
+func TestWrapWithExitCode(t *testing.T) {
+	markers := exitMarkers{start: "__S_", exit: "__E_"}
+
+	assert.Equal(t,
+		`sh -c 'printf '\''__S_'\''; cat '\''a b'\''; printf '\''__E_%d\n'\'' $?'`,
+		wrapWithExitCode("cat 'a b'", markers, true))
+	assert.Contains(t,
+		wrapWithExitCode("ls", markers, false),
+		"stty -echo -onlcr")
+}
+
+func TestWrapWithExitCodePowerShell(t *testing.T) {
+	wrapped := wrapWithExitCodePowerShell("dir", exitMarkers{start: "__S_", exit: "__E_"})
+	assert.True(t, strings.HasPrefix(wrapped, "powershell.exe -NoProfile -NonInteractive -EncodedCommand "))
+}
+
+func TestEOFReader(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "", expected: "\x04"},
+		{input: "a\n", expected: "a\n\x04"},
+		{input: "a\nb", expected: "a\nb\x04\x04"},
+	}
+	for _, tt := range tests {
+		data, err := io.ReadAll(newEOFReader(strings.NewReader(tt.input)))
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, string(data))
+	}
+}
+
+// readCanonical reads r like a pty in canonical mode does for the command
+// behind it, until a VEOF at the start of a line ends the input.
+func readCanonical(r io.Reader) (string, error) {
+	var input, line []byte
+	buf := make([]byte, 1)
+	for {
+		if _, err := r.Read(buf); err != nil {
+			return string(input), errors.New("the input never ended")
+		}
+		switch buf[0] {
+		case veof:
+			if len(line) == 0 {
+				return string(input), nil
+			}
+			input, line = append(input, line...), nil
+		case '\n':
+			input, line = append(append(input, line...), '\n'), nil
+		default:
+			line = append(line, buf[0])
+		}
+	}
+}
+
+// catRunner plays a remote cat behind a pty, printing the exit markers of
+// the command it was started with.
+type catRunner struct {
+	command *string
+}
+
+func (r *catRunner) RunCommand(cmd *exec.Cmd) error {
+	input, err := readCanonical(cmd.Stdin)
+	if err != nil {
+		return err
+	}
+	markers := regexp.MustCompile(`__MINIECS_[0-9a-f]+_(START|EXIT)_`).FindAllString(*r.command, -1)
+	_, err = io.WriteString(cmd.Stdout, markers[0]+input+markers[1]+"0\n")
+	return err
+}
+
+func TestExecuteCommandWithExitCodeEndsStdin(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "session-manager-plugin"), []byte("#!/bin/sh\n"), 0o755))
+	t.Setenv("PATH", dir)
+
+	var command string
+	mockClient := new(MockECSClient)
+	mockClient.On("ExecuteCommand", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		command = aws.ToString(args.Get(1).(*ecs.ExecuteCommandInput).Command)
+	}).Return(&ecs.ExecuteCommandOutput{Session: &types.Session{SessionId: aws.String("ecs-execute-command-1")}}, nil)
+
+	ecsResource := newECSForTesting(mockClient, "ap-northeast-1")
+	ecsResource.execRunner = &catRunner{command: &command}
+
+	for _, input := range []string{"", "one\ntwo\n", "one\ntwo"} {
+		var stdout bytes.Buffer
+		err := ecsResource.ExecuteCommandWithExitCode(ecs.ExecuteCommandInput{
+			Cluster:   aws.String("prod"),
+			Task:      aws.String("task1"),
+			Container: aws.String("app"),
+			Command:   aws.String("cat"),
+		}, ExecOptions{Stdin: strings.NewReader(input), Stdout: &stdout, Stderr: io.Discard})
+		assert.NoError(t, err)
+		assert.Equal(t, input, stdout.String())
+	}
+}
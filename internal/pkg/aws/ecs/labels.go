@@ -0,0 +1,43 @@
+package ecs
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Docker labels that let a task definition declare how to enter its
+// containers.
+const (
+	LabelShell          = "miniecs.shell"
+	LabelUser           = "miniecs.user"
+	LabelWorkDir        = "miniecs.workdir"
+	LabelHidden         = "miniecs.hidden"
+	LabelCommandsPrefix = "miniecs.commands."
+)
+
+// applyLabels copies the miniecs docker labels of a container definition
+// onto container.
+func applyLabels(container *ECSContainer, labels map[string]string) {
+	for key, value := range labels {
+		switch {
+		case key == LabelShell:
+			container.Shell = value
+		case key == LabelUser:
+			container.User = value
+		case key == LabelWorkDir:
+			container.WorkDir = value
+		case key == LabelHidden:
+			hidden, err := strconv.ParseBool(value)
+			container.Hidden = err == nil && hidden
+		case strings.HasPrefix(key, LabelCommandsPrefix):
+			name := strings.TrimPrefix(key, LabelCommandsPrefix)
+			if name == "" || value == "" {
+				continue
+			}
+			if container.Commands == nil {
+				container.Commands = map[string]string{}
+			}
+			container.Commands[name] = value
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseContainerDefinitionsLabels(t *testing.T) {
+	e := newECSForTesting(new(MockECSClient), "ap-northeast-1")
+
+	containers, err := e.parseContainerDefinitions([]types.ContainerDefinition{
+		{
+			Name: aws.String("app"),
+			DockerLabels: map[string]string{
+				"miniecs.shell":            "bash",
+				"miniecs.user":             "app",
+				"miniecs.workdir":          "/srv/app",
+				"miniecs.commands.console": "bin/rails c",
+				"miniecs.commands.":        "ignored",
+				"com.example.team":         "payments",
+			},
+		},
+		{
+			Name:         aws.String("log_router"),
+			Essential:    aws.Bool(false),
+			DockerLabels: map[string]string{"miniecs.hidden": "true"},
+		},
+	}, "")
+	assert.NoError(t, err)
+	assert.Len(t, containers, 2)
+
+	app := containers[0]
+	assert.Equal(t, "bash", app.Shell)
+	assert.Equal(t, "app", app.User)
+	assert.Equal(t, "/srv/app", app.WorkDir)
+	assert.False(t, app.Hidden)
+	assert.Equal(t, map[string]string{"console": "bin/rails c"}, app.Commands)
+
+	assert.True(t, app.Essential)
+
+	assert.True(t, containers[1].Hidden)
+	assert.False(t, containers[1].Essential)
+}
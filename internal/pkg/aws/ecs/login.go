@@ -0,0 +1,183 @@
+package ecs
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// LoginSpec describes what is started in a container on login.
+type LoginSpec struct {
+	Shell string
+	// Command runs instead of an interactive Shell when set.
+	Command string
+	User    string
+	WorkDir string
+	// Env holds KEY=VALUE pairs exported before Shell or Command starts.
+	Env []string
+	// Prompt replaces the prompt of the interactive Shell, shown in
+	// PromptColor (an ANSI SGR parameter such as "1;31") when set.
+	Prompt      string
+	PromptColor string
+	// Windows builds the command line for PowerShell or cmd.exe.
+	Windows bool
+}
+
+func (s LoginSpec) Validate() error {
+	if s.Windows && s.User != "" {
+		return fmt.Errorf("running as another user is not supported in Windows containers")
+	}
+	for _, env := range s.Env {
+		if err := ValidateEnv(env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateEnv checks that env is a KEY=VALUE pair with a portable name.
+func ValidateEnv(env string) error {
+	key, _, ok := strings.Cut(env, "=")
+	if !ok {
+		return fmt.Errorf("invalid environment variable %q, expected KEY=VALUE", env)
+	}
+	for i, r := range key {
+		letter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !letter && (i == 0 || r < '0' || r > '9') {
+			return fmt.Errorf("invalid environment variable name %q", key)
+		}
+	}
+	if key == "" {
+		return fmt.Errorf("invalid environment variable %q, expected KEY=VALUE", env)
+	}
+	return nil
+}
+
+// CommandLine returns the command passed to the execute command API for s.
+func (s LoginSpec) CommandLine() string {
+	if s.Windows {
+		return s.windowsCommandLine()
+	}
+	if s.Command == "" && s.User == "" && s.WorkDir == "" && len(s.Env) == 0 && s.Prompt == "" {
+		return s.Shell
+	}
+
+	run := s.Shell
+	if s.Command != "" {
+		run = s.Command
+	} else if s.Prompt != "" {
+		run += noRCFlag(s.Shell)
+	}
+	script := s.exports() + "exec " + run
+	if s.WorkDir != "" {
+		script = "cd " + shellQuote(s.WorkDir) + " && " + script
+	}
+	if s.User != "" {
+		// runuser needs no password and keeps the terminal, su is the
+		// fallback for images without util-linux.
+		script = fmt.Sprintf(
+			"if command -v runuser >/dev/null 2>&1; then exec runuser -u %[1]s -- sh -c %[2]s; else exec su -s /bin/sh -c %[2]s %[1]s; fi",
+			shellQuote(s.User), shellQuote(script))
+	}
+	return "sh -c " + shellQuote(script)
+}
+
+func (s LoginSpec) exports() string {
+	var b strings.Builder
+	for _, env := range s.Env {
+		key, value, _ := strings.Cut(env, "=")
+		fmt.Fprintf(&b, "export %s=%s; ", key, shellQuote(value))
+	}
+	if s.Prompt != "" && s.Command == "" {
+		prompt := shellQuote(s.posixPrompt())
+		fmt.Fprintf(&b, "export PS1=%s PROMPT=%s; ", prompt, prompt)
+	}
+	return b.String()
+}
+
+// posixPrompt colours the prompt with escapes bash keeps out of its line
+// length calculation; other shells get the raw escape sequence.
+func (s LoginSpec) posixPrompt() string {
+	if s.PromptColor == "" {
+		return s.Prompt
+	}
+	if shellName(s.Shell) == "bash" {
+		return `\[\e[` + s.PromptColor + `m\]` + s.Prompt + `\[\e[0m\]`
+	}
+	return "\x1b[" + s.PromptColor + "m" + s.Prompt + "\x1b[0m"
+}
+
+// noRCFlag keeps rc files from overriding the exported prompt.
+func noRCFlag(shell string) string {
+	switch shellName(shell) {
+	case "bash":
+		return " --norc"
+	case "zsh":
+		return " -f"
+	}
+	return ""
+}
+
+func (s LoginSpec) windowsCommandLine() string {
+	if s.WorkDir == "" && len(s.Env) == 0 && s.Prompt == "" {
+		if s.Command != "" {
+			return s.Command
+		}
+		return s.Shell
+	}
+
+	if s.Command == "" && isCmdExe(s.Shell) {
+		return s.Shell + " /K " + s.cmdExeScript()
+	}
+	script := s.powerShellScript()
+	if s.Command != "" {
+		return "powershell.exe -NoLogo -NoProfile -EncodedCommand " + encodePowerShell(script+s.Command)
+	}
+	return s.Shell + " -NoLogo -NoExit -EncodedCommand " + encodePowerShell(script)
+}
+
+func (s LoginSpec) powerShellScript() string {
+	var b strings.Builder
+	if s.WorkDir != "" {
+		fmt.Fprintf(&b, "Set-Location -LiteralPath %s; ", powerShellQuote(s.WorkDir))
+	}
+	for _, env := range s.Env {
+		key, value, _ := strings.Cut(env, "=")
+		fmt.Fprintf(&b, "$env:%s = %s; ", key, powerShellQuote(value))
+	}
+	if s.Prompt != "" && s.Command == "" {
+		prompt := powerShellQuote(s.Prompt)
+		if s.PromptColor != "" {
+			prompt = fmt.Sprintf(`"$([char]27)[%sm" + %s + "$([char]27)[0m"`, s.PromptColor, prompt)
+		}
+		fmt.Fprintf(&b, "function global:prompt { %s }; ", prompt)
+	}
+	return b.String()
+}
+
+func (s LoginSpec) cmdExeScript() string {
+	var steps []string
+	if s.WorkDir != "" {
+		steps = append(steps, fmt.Sprintf(`cd /d "%s"`, s.WorkDir))
+	}
+	for _, env := range s.Env {
+		steps = append(steps, fmt.Sprintf(`set "%s"`, env))
+	}
+	if s.Prompt != "" {
+		prompt := strings.ReplaceAll(s.Prompt, "$", "$$")
+		if s.PromptColor != "" {
+			prompt = "$E[" + s.PromptColor + "m" + prompt + "$E[0m"
+		}
+		steps = append(steps, "prompt "+prompt)
+	}
+	return strings.Join(steps, " & ")
+}
+
+func shellName(shell string) string {
+	return strings.ToLower(path.Base(strings.ReplaceAll(shell, `\`, "/")))
+}
+
+func isCmdExe(shell string) bool {
+	name := shellName(shell)
+	return name == "cmd" || name == "cmd.exe"
+}
@@ -0,0 +1,100 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginSpecCommandLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     LoginSpec
+		expected string
+	}{
+		{
+			name:     "shell only",
+			spec:     LoginSpec{Shell: "bash"},
+			expected: "bash",
+		},
+		{
+			name:     "workdir",
+			spec:     LoginSpec{Shell: "bash", WorkDir: "/srv/app"},
+			expected: `sh -c 'cd /srv/app && exec bash'`,
+		},
+		{
+			name:     "command",
+			spec:     LoginSpec{Shell: "bash", Command: "bin/rails c"},
+			expected: `sh -c 'exec bin/rails c'`,
+		},
+		{
+			name:     "windows shell only",
+			spec:     LoginSpec{Shell: "powershell.exe", Windows: true},
+			expected: "powershell.exe",
+		},
+		{
+			name:     "windows cmd.exe workdir",
+			spec:     LoginSpec{Shell: "cmd.exe", WorkDir: `C:\app`, Windows: true},
+			expected: `cmd.exe /K cd /d "C:\app"`,
+		},
+		{
+			name:     "windows powershell workdir",
+			spec:     LoginSpec{Shell: "powershell.exe", WorkDir: `C:\app`, Windows: true},
+			expected: "powershell.exe -NoLogo -NoExit -EncodedCommand " + encodePowerShell(`Set-Location -LiteralPath 'C:\app'; `),
+		},
+		{
+			name: "user",
+			spec: LoginSpec{Shell: "sh", User: "app"},
+			expected: `sh -c 'if command -v runuser >/dev/null 2>&1; then exec runuser -u app -- sh -c '\''exec sh'\''; ` +
+				`else exec su -s /bin/sh -c '\''exec sh'\'' app; fi'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.spec.CommandLine())
+		})
+	}
+}
+
+func TestLoginSpecValidate(t *testing.T) {
+	assert.NoError(t, LoginSpec{User: "app"}.Validate())
+	assert.NoError(t, LoginSpec{WorkDir: `C:\app`, Windows: true}.Validate())
+	assert.Error(t, LoginSpec{User: "app", Windows: true}.Validate())
+}
+
+func TestLoginSpecEnvAndPrompt(t *testing.T) {
+	t.Run("env", func(t *testing.T) {
+		spec := LoginSpec{Shell: "sh", Env: []string{"RAILS_ENV=production", "GREETING=hello world"}}
+		assert.Equal(t,
+			`sh -c 'export RAILS_ENV=production; export GREETING='\''hello world'\''; exec sh'`,
+			spec.CommandLine())
+	})
+
+	t.Run("bash prompt", func(t *testing.T) {
+		spec := LoginSpec{Shell: "bash", Prompt: "[prod/api] ", PromptColor: "31"}
+		commandLine := spec.CommandLine()
+		assert.Contains(t, commandLine, `export PS1=`)
+		assert.Contains(t, commandLine, `\[\e[31m\][prod/api] \[\e[0m\]`)
+		assert.Contains(t, commandLine, `exec bash --norc`)
+	})
+
+	t.Run("prompt is not set for commands", func(t *testing.T) {
+		spec := LoginSpec{Shell: "bash", Command: "bin/rails c", Prompt: "[prod/api] "}
+		assert.Equal(t, `sh -c 'exec bin/rails c'`, spec.CommandLine())
+	})
+
+	t.Run("cmd.exe", func(t *testing.T) {
+		spec := LoginSpec{Shell: "cmd.exe", Env: []string{"A=1"}, Prompt: "[prod] $ ", PromptColor: "31", Windows: true}
+		assert.Equal(t, `cmd.exe /K set "A=1" & prompt $E[31m[prod] $$ $E[0m`, spec.CommandLine())
+	})
+}
+
+func TestValidateEnv(t *testing.T) {
+	assert.NoError(t, ValidateEnv("RAILS_ENV=production"))
+	assert.NoError(t, ValidateEnv("EMPTY="))
+	assert.Error(t, ValidateEnv("NOVALUE"))
+	assert.Error(t, ValidateEnv("=value"))
+	assert.Error(t, ValidateEnv("1BAD=value"))
+	assert.Error(t, ValidateEnv("BAD-NAME=value"))
+}
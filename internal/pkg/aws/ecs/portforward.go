@@ -0,0 +1,111 @@
+package ecs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// portForwardingDocument is the Session Manager document forwarding a local
+// port to a port of the target.
+const portForwardingDocument = "AWS-StartPortForwardingSession"
+
+type SSMClient interface {
+	StartSession(ctx context.Context, params *ssm.StartSessionInput, optFns ...func(*ssm.Options)) (*ssm.StartSessionOutput, error)
+	DescribeSessions(ctx context.Context, params *ssm.DescribeSessionsInput, optFns ...func(*ssm.Options)) (*ssm.DescribeSessionsOutput, error)
+	TerminateSession(ctx context.Context, params *ssm.TerminateSessionInput, optFns ...func(*ssm.Options)) (*ssm.TerminateSessionOutput, error)
+}
+
+// PortForward forwards LocalPort on localhost to RemotePort of a container.
+type PortForward struct {
+	LocalPort  int
+	RemotePort int
+}
+
+func (f PortForward) String() string {
+	return fmt.Sprintf("%d:%d", f.LocalPort, f.RemotePort)
+}
+
+// PortForwardCommand starts a port forwarding session to container of a
+// task and returns the session-manager-plugin invocation that serves it,
+// without running it.
+func (e *ECSResource) PortForwardCommand(ctx context.Context, cluster, taskArn string, container ECSContainer, forward PortForward) (*exec.Cmd, error) {
+	session := Session{
+		Cluster:   cluster,
+		Task:      taskArn,
+		Container: container.ContainerName,
+		Command:   "port-forward " + forward.String(),
+		Started:   time.Now(),
+		ExitCode:  -1,
+		Detached:  true,
+	}
+	cmd, sessionID, err := e.portForwardCommand(ctx, cluster, taskArn, container, forward)
+	session.SessionID, session.Err = sessionID, err
+	e.reportSession(session)
+	return cmd, err
+}
+
+func (e *ECSResource) portForwardCommand(ctx context.Context, cluster, taskArn string, container ECSContainer, forward PortForward) (*exec.Cmd, string, error) {
+	if e.ssmClient == nil {
+		return nil, "", fmt.Errorf("SSM client is not initialized")
+	}
+	if container.RuntimeID == "" {
+		return nil, "", fmt.Errorf("container %s of task %s is not running", container.ContainerName, taskArn)
+	}
+
+	if _, err := exec.LookPath("session-manager-plugin"); err != nil {
+		return nil, "", e.portForwardError(cluster, taskArn, container, err)
+	}
+
+	taskID := taskArn[strings.LastIndex(taskArn, "/")+1:]
+	target := fmt.Sprintf("ecs:%s_%s_%s", cluster, taskID, container.RuntimeID)
+	out, err := e.ssmClient.StartSession(ctx, &ssm.StartSessionInput{
+		Target:       aws.String(target),
+		DocumentName: aws.String(portForwardingDocument),
+		Parameters: map[string][]string{
+			"portNumber":      {strconv.Itoa(forward.RemotePort)},
+			"localPortNumber": {strconv.Itoa(forward.LocalPort)},
+		},
+	})
+	if err != nil {
+		return nil, "", e.portForwardError(cluster, taskArn, container, err)
+	}
+	sessionID := aws.ToString(out.SessionId)
+
+	sessionInfo, err := json.Marshal(struct {
+		SessionId  *string
+		StreamUrl  *string
+		TokenValue *string
+	}{out.SessionId, out.StreamUrl, out.TokenValue})
+	if err != nil {
+		return nil, sessionID, fmt.Errorf("failed to marshal session info: %w", err)
+	}
+
+	targetJSON, err := e.buildSSMTargetJSON(target)
+	if err != nil {
+		return nil, sessionID, fmt.Errorf("failed to create target JSON: %w", err)
+	}
+
+	return e.buildSessionManagerCommand(sessionInfo, targetJSON), sessionID, nil
+}
+
+func (e *ECSResource) portForwardError(cluster, taskArn string, container ECSContainer, err error) error {
+	kind := classifyError(err)
+	if kind == nil {
+		return fmt.Errorf("failed to start port forwarding session: %w", err)
+	}
+	return &Error{
+		Kind:      kind,
+		Cluster:   cluster,
+		Task:      taskArn,
+		Container: container.ContainerName,
+		Err:       err,
+	}
+}
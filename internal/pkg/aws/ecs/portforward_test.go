@@ -0,0 +1,86 @@
+package ecs
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockSSMClient struct {
+	mock.Mock
+}
+
+func (m *MockSSMClient) StartSession(ctx context.Context, params *ssm.StartSessionInput, optFns ...func(*ssm.Options)) (*ssm.StartSessionOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*ssm.StartSessionOutput), args.Error(1)
+}
+
+func (m *MockSSMClient) DescribeSessions(ctx context.Context, params *ssm.DescribeSessionsInput, optFns ...func(*ssm.Options)) (*ssm.DescribeSessionsOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*ssm.DescribeSessionsOutput), args.Error(1)
+}
+
+func (m *MockSSMClient) TerminateSession(ctx context.Context, params *ssm.TerminateSessionInput, optFns ...func(*ssm.Options)) (*ssm.TerminateSessionOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*ssm.TerminateSessionOutput), args.Error(1)
+}
+
+func TestPortForwardCommand(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "session-manager-plugin"), []byte("#!/bin/sh\n"), 0o755))
+	t.Setenv("PATH", dir)
+
+	mockSSM := new(MockSSMClient)
+	mockSSM.On("StartSession", mock.Anything, &ssm.StartSessionInput{
+		Target:       aws.String("ecs:prod_0123abcd_0123abcd-1234567890"),
+		DocumentName: aws.String("AWS-StartPortForwardingSession"),
+		Parameters: map[string][]string{
+			"portNumber":      {"80"},
+			"localPortNumber": {"8080"},
+		},
+	}).Return(&ssm.StartSessionOutput{
+		SessionId:  aws.String("miniecs-1"),
+		StreamUrl:  aws.String("wss://ssmmessages.ap-northeast-1.amazonaws.com/v1/data-channel/miniecs-1"),
+		TokenValue: aws.String("token"),
+	}, nil)
+
+	ecsResource := newECSForTesting(new(MockECSClient), "ap-northeast-1")
+	ecsResource.ssmClient = mockSSM
+	var sessions []Session
+	ecsResource.OnSession = func(s Session) {
+		sessions = append(sessions, s)
+	}
+
+	container := ECSContainer{ContainerName: "app", RuntimeID: "0123abcd-1234567890"}
+	taskArn := "arn:aws:ecs:ap-northeast-1:123456789012:task/prod/0123abcd"
+	cmd, err := ecsResource.PortForwardCommand(context.Background(), "prod", taskArn, container, PortForward{LocalPort: 8080, RemotePort: 80})
+	require.NoError(t, err)
+	mockSSM.AssertExpectations(t)
+
+	assert.Equal(t, "StartSession", cmd.Args[3])
+	var sessionInfo map[string]string
+	require.NoError(t, json.Unmarshal([]byte(cmd.Args[1]), &sessionInfo))
+	assert.Equal(t, "miniecs-1", sessionInfo["SessionId"])
+	assert.Equal(t, "token", sessionInfo["TokenValue"])
+	assert.JSONEq(t, `{"Target": "ecs:prod_0123abcd_0123abcd-1234567890"}`, cmd.Args[5])
+
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "port-forward 8080:80", sessions[0].Command)
+	assert.Equal(t, "miniecs-1", sessions[0].SessionID)
+	assert.True(t, sessions[0].Detached)
+
+	_, err = ecsResource.PortForwardCommand(context.Background(), "prod", taskArn, ECSContainer{ContainerName: "app"}, PortForward{LocalPort: 8080, RemotePort: 80})
+	assert.ErrorContains(t, err, "is not running")
+
+	t.Setenv("PATH", t.TempDir())
+	_, err = ecsResource.PortForwardCommand(context.Background(), "prod", taskArn, container, PortForward{LocalPort: 8080, RemotePort: 80})
+	assert.ErrorIs(t, err, ErrPluginMissing)
+}
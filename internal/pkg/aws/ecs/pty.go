@@ -0,0 +1,100 @@
+package ecs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/creack/pty"
+	"golang.org/x/term"
+)
+
+// SessionRecorder receives the terminal I/O of a session started with
+// ExecuteCommandRecorded.
+type SessionRecorder interface {
+	Output(p []byte)
+	Input(p []byte)
+	Resize(width, height int)
+}
+
+// ExecuteCommandRecorded is ExecuteCommand with session-manager-plugin
+// running in a pseudo terminal, so that everything typed and shown can be
+// passed to rec.
+func (e *ECSResource) ExecuteCommandRecorded(input ecs.ExecuteCommandInput, rec SessionRecorder) error {
+	session := newSession(input, aws.ToString(input.Command))
+	sessionID, err := e.startRecordedSession(input, rec)
+	session.SessionID, session.Err = sessionID, err
+	e.reportSession(session)
+	return err
+}
+
+func (e *ECSResource) startRecordedSession(input ecs.ExecuteCommandInput, rec SessionRecorder) (string, error) {
+	cmd, sessionID, err := e.sessionCommand(input)
+	if err != nil {
+		return sessionID, err
+	}
+	if err := runInPTY(cmd, rec); err != nil {
+		return sessionID, classifyExecuteCommandError(input, err)
+	}
+	return sessionID, nil
+}
+
+// runInPTY runs cmd in a new pseudo terminal attached to the process'
+// stdin and stdout, copying all traffic to rec.
+func runInPTY(cmd *exec.Cmd, rec SessionRecorder) error {
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = nil, nil, nil
+	ptmx, err := pty.Start(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to start pseudo terminal: %w", err)
+	}
+	defer ptmx.Close()
+
+	stdinFd := int(os.Stdin.Fd())
+	if term.IsTerminal(stdinFd) {
+		resize := func() {
+			if err := pty.InheritSize(os.Stdin, ptmx); err != nil {
+				return
+			}
+			if width, height, err := term.GetSize(stdinFd); err == nil {
+				rec.Resize(width, height)
+			}
+		}
+		stop := notifyResize(resize)
+		defer stop()
+		_ = pty.InheritSize(os.Stdin, ptmx)
+
+		state, err := term.MakeRaw(stdinFd)
+		if err != nil {
+			return fmt.Errorf("failed to set terminal to raw mode: %w", err)
+		}
+		defer term.Restore(stdinFd, state)
+	}
+
+	// The copy from stdin ends with the process, when its next read fails.
+	go func() {
+		_, _ = io.Copy(ptmx, io.TeeReader(os.Stdin, recorderFunc(rec.Input)))
+	}()
+
+	_, copyErr := io.Copy(io.MultiWriter(os.Stdout, recorderFunc(rec.Output)), ptmx)
+	waitErr := cmd.Wait()
+	if waitErr != nil {
+		return waitErr
+	}
+	// Reading the pty fails with EIO once the process closed its side.
+	if copyErr != nil && !errors.Is(copyErr, syscall.EIO) && !errors.Is(copyErr, os.ErrClosed) {
+		return copyErr
+	}
+	return nil
+}
+
+type recorderFunc func(p []byte)
+
+func (f recorderFunc) Write(p []byte) (int, error) {
+	f(p)
+	return len(p), nil
+}
@@ -0,0 +1,31 @@
+//go:build !windows
+
+package ecs
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResize calls resize whenever the terminal is resized, until the
+// returned stop function is called.
+func notifyResize(resize func()) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGWINCH)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ch:
+				resize()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
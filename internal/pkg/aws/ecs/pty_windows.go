@@ -0,0 +1,8 @@
+//go:build windows
+
+package ecs
+
+// notifyResize is a no-op, Windows consoles do not signal resizes.
+func notifyResize(resize func()) (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,66 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// ActiveSessions counts the Session Manager sessions currently connected to
+// tasks, by anyone, keyed by task ID. Both ECS Exec and port forwarding
+// sessions are counted.
+func (e *ECSResource) ActiveSessions(ctx context.Context) (map[string]int, error) {
+	if e.ssmClient == nil {
+		return nil, fmt.Errorf("SSM client is not initialized")
+	}
+
+	counts := map[string]int{}
+	paginator := ssm.NewDescribeSessionsPaginator(e.ssmClient, &ssm.DescribeSessionsInput{
+		State: types.SessionStateActive,
+	})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe active sessions: %w", err)
+		}
+		for _, session := range out.Sessions {
+			if taskID, ok := sessionTaskID(aws.ToString(session.Target)); ok {
+				counts[taskID]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+// TerminateSession ends the session with sessionID, such as one started by
+// SessionCommand that is never attached to.
+func (e *ECSResource) TerminateSession(ctx context.Context, sessionID string) error {
+	if e.ssmClient == nil {
+		return fmt.Errorf("SSM client is not initialized")
+	}
+	if _, err := e.ssmClient.TerminateSession(ctx, &ssm.TerminateSessionInput{
+		SessionId: aws.String(sessionID),
+	}); err != nil {
+		return fmt.Errorf("failed to terminate session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// sessionTaskID returns the task ID of an ecs:<cluster>_<taskId>_<runtimeId>
+// session target. Cluster names may contain underscores, task and runtime
+// IDs do not.
+func sessionTaskID(target string) (string, bool) {
+	rest, ok := strings.CutPrefix(target, "ecs:")
+	if !ok {
+		return "", false
+	}
+	parts := strings.Split(rest, "_")
+	if len(parts) < 3 {
+		return "", false
+	}
+	return parts[len(parts)-2], true
+}
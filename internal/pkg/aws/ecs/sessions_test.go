@@ -0,0 +1,73 @@
+package ecs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActiveSessions(t *testing.T) {
+	mockSSM := new(MockSSMClient)
+	mockSSM.On("DescribeSessions", mock.Anything, &ssm.DescribeSessionsInput{
+		State: types.SessionStateActive,
+	}).Return(&ssm.DescribeSessionsOutput{
+		Sessions: []types.Session{
+			{Target: aws.String("ecs:prod_task1_task1-111")},
+			{Target: aws.String("ecs:prod_task1_task1-222")},
+		},
+		NextToken: aws.String("page2"),
+	}, nil)
+	mockSSM.On("DescribeSessions", mock.Anything, &ssm.DescribeSessionsInput{
+		State:     types.SessionStateActive,
+		NextToken: aws.String("page2"),
+	}).Return(&ssm.DescribeSessionsOutput{
+		Sessions: []types.Session{
+			{Target: aws.String("ecs:my_prod_cluster_task2_task2-111")},
+			{Target: aws.String("i-0123456789abcdef0")},
+		},
+	}, nil)
+
+	ecsResource := newECSForTesting(new(MockECSClient), "ap-northeast-1")
+	ecsResource.ssmClient = mockSSM
+
+	sessions, err := ecsResource.ActiveSessions(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"task1": 2, "task2": 1}, sessions)
+}
+
+func TestActiveSessionsError(t *testing.T) {
+	mockSSM := new(MockSSMClient)
+	mockSSM.On("DescribeSessions", mock.Anything, mock.Anything).
+		Return((*ssm.DescribeSessionsOutput)(nil), errors.New("access denied"))
+
+	ecsResource := newECSForTesting(new(MockECSClient), "ap-northeast-1")
+	ecsResource.ssmClient = mockSSM
+
+	_, err := ecsResource.ActiveSessions(context.Background())
+	assert.ErrorContains(t, err, "failed to describe active sessions")
+}
+
+func TestTerminateSession(t *testing.T) {
+	mockSSM := new(MockSSMClient)
+	mockSSM.On("TerminateSession", mock.Anything, &ssm.TerminateSessionInput{
+		SessionId: aws.String("ecs-execute-command-1"),
+	}).Return(&ssm.TerminateSessionOutput{}, nil)
+	mockSSM.On("TerminateSession", mock.Anything, &ssm.TerminateSessionInput{
+		SessionId: aws.String("ecs-execute-command-2"),
+	}).Return((*ssm.TerminateSessionOutput)(nil), errors.New("access denied"))
+
+	ecsResource := newECSForTesting(new(MockECSClient), "ap-northeast-1")
+	assert.Error(t, ecsResource.TerminateSession(context.Background(), "ecs-execute-command-1"))
+
+	ecsResource.ssmClient = mockSSM
+	assert.NoError(t, ecsResource.TerminateSession(context.Background(), "ecs-execute-command-1"))
+	assert.ErrorContains(t, ecsResource.TerminateSession(context.Background(), "ecs-execute-command-2"), "access denied")
+	mockSSM.AssertExpectations(t)
+}
@@ -0,0 +1,54 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+)
+
+type LogsClient interface {
+	GetLogEvents(ctx context.Context, params *cloudwatchlogs.GetLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetLogEventsOutput, error)
+}
+
+// Event is a line written by a container.
+type Event struct {
+	Time    time.Time
+	Message string
+}
+
+type LogsResource struct {
+	client LogsClient
+}
+
+func NewLogs(cfg aws.Config, region string) *LogsResource {
+	return &LogsResource{
+		client: cloudwatchlogs.NewFromConfig(cfg, func(o *cloudwatchlogs.Options) {
+			o.Region = region
+		}),
+	}
+}
+
+// Tail returns the last limit events of a log stream, oldest first.
+func (l *LogsResource) Tail(ctx context.Context, group, stream string, limit int32) ([]Event, error) {
+	out, err := l.client.GetLogEvents(ctx, &cloudwatchlogs.GetLogEventsInput{
+		LogGroupName:  aws.String(group),
+		LogStreamName: aws.String(stream),
+		Limit:         aws.Int32(limit),
+		StartFromHead: aws.Bool(false),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log events of %s %s: %w", group, stream, err)
+	}
+
+	events := make([]Event, 0, len(out.Events))
+	for _, event := range out.Events {
+		events = append(events, Event{
+			Time:    time.UnixMilli(aws.ToInt64(event.Timestamp)),
+			Message: aws.ToString(event.Message),
+		})
+	}
+	return events, nil
+}
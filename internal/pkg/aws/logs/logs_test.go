@@ -0,0 +1,46 @@
+package logs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLogsClient struct {
+	mock.Mock
+}
+
+func (m *MockLogsClient) GetLogEvents(ctx context.Context, params *cloudwatchlogs.GetLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetLogEventsOutput, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(*cloudwatchlogs.GetLogEventsOutput), args.Error(1)
+}
+
+func TestTail(t *testing.T) {
+	mockClient := new(MockLogsClient)
+	logsResource := &LogsResource{client: mockClient}
+
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mockClient.On("GetLogEvents", mock.Anything, &cloudwatchlogs.GetLogEventsInput{
+		LogGroupName:  aws.String("/ecs/api"),
+		LogStreamName: aws.String("ecs/app/task-id"),
+		Limit:         aws.Int32(100),
+		StartFromHead: aws.Bool(false),
+	}).Return(&cloudwatchlogs.GetLogEventsOutput{
+		Events: []types.OutputLogEvent{
+			{Timestamp: aws.Int64(started.UnixMilli()), Message: aws.String("booting")},
+			{Timestamp: aws.Int64(started.Add(time.Second).UnixMilli()), Message: aws.String("listening on :8080")},
+		},
+	}, nil)
+
+	events, err := logsResource.Tail(context.Background(), "/ecs/api", "ecs/app/task-id", 100)
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.True(t, started.Equal(events[0].Time))
+	assert.Equal(t, "listening on :8080", events[1].Message)
+}
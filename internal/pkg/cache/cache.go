@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ShellCache remembers the shell detected for a container image, keyed by
+// image digest, so that containers are only probed once per image.
+type ShellCache struct {
+	path   string
+	Shells map[string]string `json:"shells"`
+}
+
+// Dir returns the directory miniecs keeps its cache files in.
+func Dir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find cache directory: %w", err)
+	}
+	return filepath.Join(dir, "miniecs"), nil
+}
+
+func LoadShellCache() (*ShellCache, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return loadShellCache(filepath.Join(dir, "shells.json"))
+}
+
+func loadShellCache(path string) (*ShellCache, error) {
+	c := &ShellCache{path: path, Shells: map[string]string{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shell cache: %w", err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("failed to parse shell cache %s: %w", path, err)
+	}
+	if c.Shells == nil {
+		c.Shells = map[string]string{}
+	}
+	return c, nil
+}
+
+func (c *ShellCache) Get(digest string) (string, bool) {
+	shell, ok := c.Shells[digest]
+	return shell, ok
+}
+
+func (c *ShellCache) Set(digest, shell string) {
+	c.Shells[digest] = shell
+}
+
+func (c *ShellCache) Save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode shell cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(c.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write shell cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShellCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "miniecs", "shells.json")
+
+	c, err := loadShellCache(path)
+	assert.NoError(t, err)
+	_, ok := c.Get("sha256:abc")
+	assert.False(t, ok)
+
+	c.Set("sha256:abc", "bash")
+	assert.NoError(t, c.Save())
+
+	reloaded, err := loadShellCache(path)
+	assert.NoError(t, err)
+	shell, ok := reloaded.Get("sha256:abc")
+	assert.True(t, ok)
+	assert.Equal(t, "bash", shell)
+}
@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxRecentTargets bounds the number of targets kept in the history.
+const maxRecentTargets = 100
+
+// RecentTarget is a container logged in to before. The task is not kept
+// since it changes with every deployment.
+type RecentTarget struct {
+	Region    string    `json:"region"`
+	Account   string    `json:"account,omitempty"`
+	Cluster   string    `json:"cluster"`
+	Service   string    `json:"service"`
+	Container string    `json:"container"`
+	Count     int       `json:"count"`
+	LastUsed  time.Time `json:"last_used"`
+}
+
+func (t RecentTarget) key() string {
+	return t.Account + "|" + t.Region + "|" + t.Cluster + "|" + t.Service + "|" + t.Container
+}
+
+// RecentTargets is the login history, most recently used first.
+type RecentTargets struct {
+	path    string
+	now     func() time.Time
+	Targets []RecentTarget `json:"targets"`
+}
+
+func LoadRecentTargets() (*RecentTargets, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return loadRecentTargets(filepath.Join(dir, "recent.json"))
+}
+
+func loadRecentTargets(path string) (*RecentTargets, error) {
+	r := &RecentTargets{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read login history: %w", err)
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("failed to parse login history %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Add moves target to the front of the history and counts its use.
+func (r *RecentTargets) Add(target RecentTarget) {
+	target.LastUsed = r.timeNow()
+	target.Count = 1
+	for i, existing := range r.Targets {
+		if existing.key() == target.key() {
+			target.Count = existing.Count + 1
+			r.Targets = append(r.Targets[:i], r.Targets[i+1:]...)
+			break
+		}
+	}
+	r.Targets = append([]RecentTarget{target}, r.Targets...)
+	if len(r.Targets) > maxRecentTargets {
+		r.Targets = r.Targets[:maxRecentTargets]
+	}
+}
+
+// Last returns the most recently used target for which match returns true.
+func (r *RecentTargets) Last(match func(RecentTarget) bool) (RecentTarget, bool) {
+	for _, target := range r.Targets {
+		if match(target) {
+			return target, true
+		}
+	}
+	return RecentTarget{}, false
+}
+
+// Score ranks targets by how often and how recently they were used. Each
+// use counts less the longer ago the target was last used, halving every
+// week. Targets never used score 0.
+func (r *RecentTargets) Score(match func(RecentTarget) bool) float64 {
+	const halfLife = 7 * 24 * time.Hour
+
+	var best float64
+	for _, target := range r.Targets {
+		if !match(target) {
+			continue
+		}
+		age := r.timeNow().Sub(target.LastUsed)
+		score := float64(target.Count) * math.Exp2(-float64(age)/float64(halfLife))
+		best = math.Max(best, score)
+	}
+	return best
+}
+
+func (r *RecentTargets) timeNow() time.Time {
+	if r.now == nil {
+		return time.Now()
+	}
+	return r.now()
+}
+
+func (r *RecentTargets) Save() error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode login history: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write login history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecentTargets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "miniecs", "recent.json")
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	r, err := loadRecentTargets(path)
+	assert.NoError(t, err)
+	r.now = func() time.Time { return now }
+
+	api := RecentTarget{Region: "ap-northeast-1", Cluster: "prod", Service: "api", Container: "app"}
+	worker := RecentTarget{Region: "ap-northeast-1", Cluster: "prod", Service: "worker", Container: "app"}
+	r.Add(api)
+	now = now.Add(time.Hour)
+	r.Add(worker)
+	now = now.Add(time.Hour)
+	r.Add(api)
+	assert.NoError(t, r.Save())
+
+	reloaded, err := loadRecentTargets(path)
+	assert.NoError(t, err)
+	assert.Len(t, reloaded.Targets, 2)
+	assert.Equal(t, "api", reloaded.Targets[0].Service)
+	assert.Equal(t, 2, reloaded.Targets[0].Count)
+	assert.Equal(t, now, reloaded.Targets[0].LastUsed)
+
+	last, ok := reloaded.Last(func(t RecentTarget) bool { return t.Service == "worker" })
+	assert.True(t, ok)
+	assert.Equal(t, 1, last.Count)
+	_, ok = reloaded.Last(func(t RecentTarget) bool { return t.Region == "us-east-1" })
+	assert.False(t, ok)
+}
+
+func TestRecentTargetsLimit(t *testing.T) {
+	r := &RecentTargets{}
+	for i := range maxRecentTargets + 10 {
+		r.Add(RecentTarget{Cluster: "prod", Service: string(rune('a' + i%26)), Container: string(rune('a' + i/26))})
+	}
+	assert.Len(t, r.Targets, maxRecentTargets)
+}
+
+func TestRecentTargetsScore(t *testing.T) {
+	now := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	r := &RecentTargets{
+		now: func() time.Time { return now },
+		Targets: []RecentTarget{
+			{Service: "fresh", Count: 1, LastUsed: now},
+			{Service: "frequent", Count: 8, LastUsed: now.Add(-14 * 24 * time.Hour)},
+		},
+	}
+	service := func(name string) func(RecentTarget) bool {
+		return func(t RecentTarget) bool { return t.Service == name }
+	}
+
+	assert.InDelta(t, 1, r.Score(service("fresh")), 0.001)
+	assert.InDelta(t, 2, r.Score(service("frequent")), 0.001)
+	assert.Zero(t, r.Score(service("never")))
+}
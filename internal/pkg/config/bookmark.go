@@ -0,0 +1,137 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Bookmark is a named entry point, used as `miniecs login @name`. The task
+// is chosen at login time among the running tasks of the service.
+type Bookmark struct {
+	Region    string `yaml:"region,omitempty"`
+	Cluster   string `yaml:"cluster"`
+	Service   string `yaml:"service,omitempty"`
+	Container string `yaml:"container,omitempty"`
+	Shell     string `yaml:"shell,omitempty"`
+	User      string `yaml:"user,omitempty"`
+	WorkDir   string `yaml:"workdir,omitempty"`
+	Command   string `yaml:"command,omitempty"`
+}
+
+const bookmarksKey = "bookmarks"
+
+// SaveBookmark adds or replaces the bookmark called name in the config
+// file, keeping the rest of the file as it is.
+func SaveBookmark(name string, bookmark Bookmark) error {
+	var value yaml.Node
+	if err := value.Encode(bookmark); err != nil {
+		return fmt.Errorf("failed to encode bookmark: %w", err)
+	}
+	return updateFile(func(root *yaml.Node) error {
+		bookmarks := mappingValue(root, bookmarksKey, true)
+		setMappingValue(bookmarks, name, &value)
+		return nil
+	})
+}
+
+// RemoveBookmark deletes the bookmark called name from the config file.
+func RemoveBookmark(name string) error {
+	return updateFile(func(root *yaml.Node) error {
+		bookmarks := mappingValue(root, bookmarksKey, false)
+		if bookmarks == nil || !deleteMappingValue(bookmarks, name) {
+			return fmt.Errorf("no bookmark named %q", name)
+		}
+		return nil
+	})
+}
+
+// updateFile applies update to the top-level mapping of the config file and
+// writes it back.
+func updateFile(update func(root *yaml.Node) error) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to update config %s: top level is not a mapping", path)
+	}
+
+	if err := update(root); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// mappingValue returns the mapping stored under key in m, adding an empty
+// one when create is set.
+func mappingValue(m *yaml.Node, key string, create bool) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			value := m.Content[i+1]
+			if value.Kind != yaml.MappingNode && create {
+				*value = yaml.Node{Kind: yaml.MappingNode}
+			}
+			return value
+		}
+	}
+	if !create {
+		return nil
+	}
+	value := &yaml.Node{Kind: yaml.MappingNode}
+	setMappingValue(m, key, value)
+	return value
+}
+
+func setMappingValue(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+func deleteMappingValue(m *yaml.Node, key string) bool {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
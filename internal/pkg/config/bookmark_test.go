@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveBookmark(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "miniecs", "config.yaml")
+	t.Setenv(EnvPath, path)
+
+	api := Bookmark{Region: "ap-northeast-1", Cluster: "prod", Service: "api", Container: "app", Shell: "bash"}
+	assert.NoError(t, SaveBookmark("api-prod", api))
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]Bookmark{"api-prod": api}, cfg.Bookmarks)
+
+	api.Shell = "zsh"
+	assert.NoError(t, SaveBookmark("api-prod", api))
+	assert.NoError(t, SaveBookmark("worker", Bookmark{Cluster: "prod", Service: "worker"}))
+
+	cfg, err = Load()
+	assert.NoError(t, err)
+	assert.Len(t, cfg.Bookmarks, 2)
+	assert.Equal(t, "zsh", cfg.Bookmarks["api-prod"].Shell)
+
+	assert.NoError(t, RemoveBookmark("api-prod"))
+	assert.ErrorContains(t, RemoveBookmark("api-prod"), `no bookmark named "api-prod"`)
+
+	cfg, err = Load()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"worker"}, keys(cfg.Bookmarks))
+}
+
+func TestSaveBookmarkKeepsConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(EnvPath, path)
+	original := "# team settings\nsidecars:\n  show: true # always\n"
+	assert.NoError(t, os.WriteFile(path, []byte(original), 0o600))
+
+	assert.NoError(t, SaveBookmark("api", Bookmark{Cluster: "prod", Service: "api"}))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "# team settings")
+	assert.Contains(t, string(data), "show: true # always")
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.True(t, cfg.Sidecars.Show)
+	assert.Equal(t, "api", cfg.Bookmarks["api"].Service)
+}
+
+func keys(m map[string]Bookmark) []string {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
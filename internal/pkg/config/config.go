@@ -0,0 +1,233 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPath overrides the location of the config file.
+const EnvPath = "MINIECS_CONFIG"
+
+type Config struct {
+	Sidecars   SidecarConfig            `yaml:"sidecars"`
+	Prompt     PromptConfig             `yaml:"prompt"`
+	Production ProductionConfig         `yaml:"production"`
+	Contexts   map[string]ContextConfig `yaml:"contexts"`
+	// Policy is the path of the exec policy file, policy.yaml next to the
+	// config file when empty.
+	Policy string       `yaml:"policy"`
+	Audit  AuditConfig  `yaml:"audit"`
+	Redact RedactConfig `yaml:"redact"`
+	// Bookmarks are maintained with `miniecs bookmark`.
+	Bookmarks map[string]Bookmark `yaml:"bookmarks"`
+	Picker    PickerConfig        `yaml:"picker"`
+}
+
+// PickerConfig chooses how targets are selected.
+type PickerConfig struct {
+	// Backend is builtin, fzf, sk or prompt. When empty, prompt is used
+	// without a terminal and builtin otherwise.
+	Backend string `yaml:"backend"`
+	// Args are extra arguments for fzf and sk.
+	Args []string `yaml:"args"`
+	// Format is a Go template for the picker lines. Fields separated by
+	// spaces are aligned in columns.
+	Format string `yaml:"format"`
+}
+
+// RedactConfig controls the masking of secrets in recordings, the audit log
+// and fan-out exec output.
+type RedactConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Patterns are extra regular expressions to mask, only the last
+	// capture group when they have any.
+	Patterns []string `yaml:"patterns"`
+}
+
+// AuditConfig controls the local log of execute command sessions.
+type AuditConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Path is the JSONL file entries are appended to, audit.jsonl next to
+	// the config file when empty.
+	Path string `yaml:"path"`
+}
+
+// ContextConfig is a named set of defaults chosen with --context.
+type ContextConfig struct {
+	Region string `yaml:"region"`
+	// Production treats every cluster of the context as production.
+	Production bool `yaml:"production"`
+	// Readonly forbids subcommands that change resources.
+	Readonly bool `yaml:"readonly"`
+}
+
+// SidecarConfig decides which containers are treated as sidecars and hidden
+// from the picker until they are toggled back on.
+type SidecarConfig struct {
+	// Show lists sidecars in the picker from the start.
+	Show bool `yaml:"show"`
+	// Patterns are globs matched against the container name and the
+	// repository name of its image.
+	Patterns []string `yaml:"patterns"`
+	// NonEssential treats every container with essential set to false as a
+	// sidecar.
+	NonEssential bool `yaml:"non_essential"`
+}
+
+// PromptConfig sets the shell prompt of login sessions so that everybody can
+// see which environment they are in.
+type PromptConfig struct {
+	// Template is a Go template rendered with Region, Cluster, Service, Task
+	// and Container. An empty template keeps the container's own prompt.
+	Template string `yaml:"template"`
+	// Color and ProductionColor are ANSI SGR parameters, e.g. "1;31".
+	Color           string `yaml:"color"`
+	ProductionColor string `yaml:"production_color"`
+}
+
+// ProductionConfig marks clusters as production.
+type ProductionConfig struct {
+	// Clusters are globs matched against cluster names, none by default.
+	Clusters []string `yaml:"clusters"`
+	// Confirm asks to type the cluster name before a session starts in a
+	// production cluster.
+	Confirm bool `yaml:"confirm"`
+}
+
+func (c ProductionConfig) Matches(cluster string) bool {
+	for _, pattern := range c.Clusters {
+		if ok, _ := path.Match(pattern, cluster); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func Default() *Config {
+	return &Config{
+		Sidecars: SidecarConfig{
+			Patterns: []string{
+				"datadog-agent",
+				"*aws-otel-collector*",
+				"envoy",
+				"*appmesh-envoy*",
+				"log_router",
+				"*fluent-bit*",
+				"xray-daemon",
+				"*aws-xray-daemon*",
+			},
+			NonEssential: true,
+		},
+		Prompt: PromptConfig{
+			ProductionColor: "1;31",
+		},
+		// No cluster is production until the config names some.
+		Production: ProductionConfig{
+			Confirm: true,
+		},
+		Audit: AuditConfig{
+			Enabled: true,
+		},
+		Redact: RedactConfig{
+			Enabled: true,
+		},
+	}
+}
+
+// Path returns the config file location, $MINIECS_CONFIG or
+// config.yaml in the miniecs user config directory.
+func Path() (string, error) {
+	if path := os.Getenv(EnvPath); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory: %w", err)
+	}
+	return filepath.Join(dir, "miniecs", "config.yaml"), nil
+}
+
+// PolicyPath returns the location of the exec policy file.
+func (c *Config) PolicyPath() (string, error) {
+	return pathNextToConfig(c.Policy, "policy.yaml")
+}
+
+// AuditPath returns the location of the audit log.
+func (c *Config) AuditPath() (string, error) {
+	return pathNextToConfig(c.Audit.Path, "audit.jsonl")
+}
+
+func pathNextToConfig(configured, name string) (string, error) {
+	if configured != "" {
+		return configured, nil
+	}
+	path, err := Path()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), name), nil
+}
+
+// Load reads the config file, falling back to Default when there is none.
+// Settings missing from the file keep their default values.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return load(path)
+}
+
+func load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// IsSidecar reports whether a container with the given name, image and
+// essential flag is a sidecar according to c.
+func (c SidecarConfig) IsSidecar(name, image string, essential bool) bool {
+	if c.NonEssential && !essential {
+		return true
+	}
+	repository := ImageRepository(image)
+	for _, pattern := range c.Patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, repository); ok && repository != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ImageRepository returns the last path element of an image reference
+// without registry, tag or digest, e.g. "aws-otel-collector" for
+// "public.ecr.aws/aws-observability/aws-otel-collector:v0.40.0".
+func ImageRepository(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	image = image[strings.LastIndex(image, "/")+1:]
+	if i := strings.Index(image, ":"); i >= 0 {
+		image = image[:i]
+	}
+	return image
+}
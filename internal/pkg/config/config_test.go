@@ -0,0 +1,105 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		cfg, err := load(filepath.Join(t.TempDir(), "config.yaml"))
+		assert.NoError(t, err)
+		assert.Equal(t, Default(), cfg)
+	})
+
+	t.Run("partial file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		assert.NoError(t, os.WriteFile(path, []byte("sidecars:\n  show: true\n"), 0o600))
+
+		cfg, err := load(path)
+		assert.NoError(t, err)
+		assert.True(t, cfg.Sidecars.Show)
+		assert.Equal(t, Default().Sidecars.Patterns, cfg.Sidecars.Patterns)
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		assert.NoError(t, os.WriteFile(path, []byte("sidecars: ["), 0o600))
+
+		_, err := load(path)
+		assert.Error(t, err)
+	})
+}
+
+func TestPath(t *testing.T) {
+	t.Setenv(EnvPath, "/tmp/miniecs.yaml")
+	path, err := Path()
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/miniecs.yaml", path)
+}
+
+func TestIsSidecar(t *testing.T) {
+	sidecars := Default().Sidecars
+
+	tests := []struct {
+		name      string
+		container string
+		image     string
+		essential bool
+		expected  bool
+	}{
+		{name: "app", container: "app", image: "123456789012.dkr.ecr.ap-northeast-1.amazonaws.com/app:v1", essential: true, expected: false},
+		{name: "by name", container: "datadog-agent", image: "datadog/agent:7", essential: true, expected: true},
+		{name: "by image", container: "otel", image: "public.ecr.aws/aws-observability/aws-otel-collector:v0.40.0", essential: true, expected: true},
+		{name: "by digest image", container: "router", image: "amazon/aws-for-fluent-bit@sha256:abc", essential: true, expected: true},
+		{name: "non essential", container: "migrate", image: "app:v1", essential: false, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, sidecars.IsSidecar(tt.container, tt.image, tt.essential))
+		})
+	}
+}
+
+func TestProductionMatches(t *testing.T) {
+	production := Default().Production
+	assert.False(t, production.Matches("api-prod"))
+
+	production.Clusters = []string{"*prod*"}
+	assert.True(t, production.Matches("api-prod"))
+	assert.True(t, production.Matches("production"))
+	assert.False(t, production.Matches("staging"))
+}
+
+func TestPolicyPath(t *testing.T) {
+	t.Setenv(EnvPath, "/etc/miniecs/config.yaml")
+
+	path, err := Default().PolicyPath()
+	assert.NoError(t, err)
+	assert.Equal(t, "/etc/miniecs/policy.yaml", path)
+
+	cfg := Default()
+	cfg.Policy = "/srv/policy.yaml"
+	path, err = cfg.PolicyPath()
+	assert.NoError(t, err)
+	assert.Equal(t, "/srv/policy.yaml", path)
+}
+
+func TestAuditPath(t *testing.T) {
+	t.Setenv(EnvPath, "/etc/miniecs/config.yaml")
+
+	cfg := Default()
+	assert.True(t, cfg.Audit.Enabled)
+	path, err := cfg.AuditPath()
+	assert.NoError(t, err)
+	assert.Equal(t, "/etc/miniecs/audit.jsonl", path)
+
+	cfg.Audit.Path = "/var/log/miniecs.jsonl"
+	path, err = cfg.AuditPath()
+	assert.NoError(t, err)
+	assert.Equal(t, "/var/log/miniecs.jsonl", path)
+}
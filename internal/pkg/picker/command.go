@@ -0,0 +1,99 @@
+package picker
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Command runs an external fuzzy finder such as fzf or sk. The labels are
+// fed to it on stdin, each prefixed with its index in a hidden field, and
+// the chosen lines are read back from stdout.
+type Command struct {
+	Name string
+	// Args are passed before the options set by Pick, so that they can
+	// add key bindings, layouts and the like.
+	Args []string
+}
+
+func (c *Command) Pick(labels []string, opts Options) ([]int, error) {
+	selected, _, err := c.PickKey(labels, opts, nil)
+	return selected, err
+}
+
+func (c *Command) PickKey(labels []string, opts Options, keys []string) ([]int, string, error) {
+	path, err := exec.LookPath(c.Name)
+	if err != nil {
+		return nil, "", fmt.Errorf("picker %s not found: %w", c.Name, err)
+	}
+
+	args := append([]string{}, c.Args...)
+	args = append(args, "--ansi", "--delimiter", "\t", "--with-nth", "2..")
+	if opts.Multi {
+		args = append(args, "--multi")
+	}
+	if opts.Header != "" {
+		args = append(args, "--header", opts.Header)
+	}
+	if len(keys) > 0 {
+		args = append(args, "--expect", strings.Join(keys, ","))
+	}
+
+	var input bytes.Buffer
+	for i, label := range labels {
+		fmt.Fprintf(&input, "%d\t%s\n", i, label)
+	}
+
+	var output bytes.Buffer
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = &input
+	cmd.Stdout = &output
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		// Both fzf and sk exit with 1 when nothing matched and with 130
+		// when interrupted.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && (exitErr.ExitCode() == 1 || exitErr.ExitCode() == 130) {
+			return nil, "", ErrAbort
+		}
+		return nil, "", fmt.Errorf("picker %s failed: %w", c.Name, err)
+	}
+
+	// With --expect, the first line is the key that accepted the
+	// selection, empty for Enter.
+	var key string
+	if len(keys) > 0 {
+		line, err := output.ReadString('\n')
+		if err != nil {
+			return nil, "", ErrAbort
+		}
+		key = strings.TrimSpace(line)
+	}
+	selected, err := parseSelection(&output, len(labels))
+	return selected, key, err
+}
+
+func parseSelection(output *bytes.Buffer, count int) ([]int, error) {
+	var selected []int
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		field, _, _ := strings.Cut(scanner.Text(), "\t")
+		if field == "" {
+			continue
+		}
+		idx, err := strconv.Atoi(field)
+		if err != nil || idx < 0 || idx >= count {
+			return nil, fmt.Errorf("unexpected picker output %q", scanner.Text())
+		}
+		selected = append(selected, idx)
+	}
+	if len(selected) == 0 {
+		return nil, ErrAbort
+	}
+	return selected, nil
+}
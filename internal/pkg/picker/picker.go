@@ -0,0 +1,104 @@
+package picker
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+// ErrAbort is returned when the user leaves a picker without choosing.
+var ErrAbort = fuzzyfinder.ErrAbort
+
+// Backends accepted by New.
+const (
+	BackendBuiltin = "builtin"
+	BackendFzf     = "fzf"
+	BackendSkim    = "sk"
+	BackendPrompt  = "prompt"
+)
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// StripANSI removes the colours from s.
+func StripANSI(s string) string {
+	return ansiEscape.ReplaceAllString(s, "")
+}
+
+func stripAll(labels []string) []string {
+	stripped := make([]string, len(labels))
+	for i, label := range labels {
+		stripped[i] = StripANSI(label)
+	}
+	return stripped
+}
+
+// Options controls a single Pick.
+type Options struct {
+	// Header is shown above the entries.
+	Header string
+	// Multi allows choosing several entries.
+	Multi bool
+	// Preview describes the entry at an index, for backends with a
+	// preview window.
+	Preview func(i int) string
+}
+
+// Picker lets the user choose among labels and returns the indices of the
+// chosen ones.
+type Picker interface {
+	Pick(labels []string, opts Options) ([]int, error)
+}
+
+// KeyPicker is a Picker that can accept the selection with other keys than
+// Enter, such as ctrl-l.
+type KeyPicker interface {
+	Picker
+	// PickKey is Pick that also returns the key of keys that accepted the
+	// selection, empty for Enter.
+	PickKey(labels []string, opts Options, keys []string) ([]int, string, error)
+}
+
+// New returns the picker for backend. args are passed on to external
+// pickers.
+func New(backend string, args []string) (Picker, error) {
+	switch backend {
+	case BackendBuiltin:
+		return Fuzzy{}, nil
+	case BackendFzf, BackendSkim:
+		return &Command{Name: backend, Args: args}, nil
+	case BackendPrompt:
+		return NewPrompt(), nil
+	}
+	return nil, fmt.Errorf("unknown picker %q, expected %s, %s, %s or %s",
+		backend, BackendBuiltin, BackendFzf, BackendSkim, BackendPrompt)
+}
+
+// Fuzzy is the built-in fuzzy finder, which shows labels without colours.
+type Fuzzy struct{}
+
+func (Fuzzy) Pick(labels []string, opts Options) ([]int, error) {
+	labels = stripAll(labels)
+	options := []fuzzyfinder.Option{}
+	if opts.Header != "" {
+		options = append(options, fuzzyfinder.WithHeader(opts.Header))
+	}
+	if opts.Preview != nil {
+		options = append(options, fuzzyfinder.WithPreviewWindow(func(i, w, h int) string {
+			if i == -1 {
+				return ""
+			}
+			return opts.Preview(i)
+		}))
+	}
+	label := func(i int) string { return labels[i] }
+
+	if opts.Multi {
+		return fuzzyfinder.FindMulti(labels, label, options...)
+	}
+	idx, err := fuzzyfinder.Find(labels, label, options...)
+	if err != nil {
+		return nil, err
+	}
+	return []int{idx}, nil
+}
@@ -0,0 +1,164 @@
+package picker
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	for _, backend := range []string{BackendBuiltin, BackendFzf, BackendSkim, BackendPrompt} {
+		p, err := New(backend, nil)
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+	}
+	_, err := New("peco", nil)
+	assert.ErrorContains(t, err, `unknown picker "peco"`)
+}
+
+// fakeFinder installs a fake fzf on PATH running script and returns the
+// file its arguments are written to.
+func fakeFinder(t *testing.T, script string) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake finder is a shell script")
+	}
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	body := "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + argsFile + "\n" + script + "\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "fzf"), []byte(body), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return argsFile
+}
+
+func TestCommandPick(t *testing.T) {
+	argsFile := fakeFinder(t, "sed -n '2p;3p'")
+
+	p := &Command{Name: BackendFzf, Args: []string{"--height=40%"}}
+	selected, err := p.Pick([]string{"prod api app", "prod api envoy", "stg api app"}, Options{Header: "Select", Multi: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, selected)
+
+	args, err := os.ReadFile(argsFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "--height=40%\n--ansi\n--delimiter\n\t\n--with-nth\n2..\n--multi\n--header\nSelect\n", string(args))
+}
+
+func TestCommandPickKey(t *testing.T) {
+	argsFile := fakeFinder(t, "printf 'ctrl-l\\n1\\tprod api envoy\\n'")
+
+	selected, key, err := (&Command{Name: BackendFzf}).PickKey([]string{"prod api app", "prod api envoy"}, Options{}, []string{"ctrl-l", "ctrl-d"})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, selected)
+	assert.Equal(t, "ctrl-l", key)
+
+	args, err := os.ReadFile(argsFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(args), "--expect\nctrl-l,ctrl-d\n")
+}
+
+func TestCommandPickAbort(t *testing.T) {
+	fakeFinder(t, "exit 130")
+
+	_, err := (&Command{Name: BackendFzf}).Pick([]string{"a"}, Options{})
+	assert.ErrorIs(t, err, ErrAbort)
+}
+
+func TestCommandPickMissing(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	_, err := (&Command{Name: BackendSkim}).Pick([]string{"a"}, Options{})
+	assert.ErrorContains(t, err, "picker sk not found")
+}
+
+func TestPromptPick(t *testing.T) {
+	var out bytes.Buffer
+	p := newPrompt(strings.NewReader("4\n2\n1,3\n"), &out)
+	labels := []string{"prod api app", "prod worker app", "stg api app"}
+
+	selected, err := p.Pick(labels, Options{Header: "Select a container"})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, selected)
+	assert.Contains(t, out.String(), "Select a container\n1) prod api app\n2) prod worker app\n3) stg api app\n")
+	assert.Contains(t, out.String(), `invalid selection "4"`)
+
+	selected, err = p.Pick(labels, Options{Multi: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 2}, selected)
+
+	_, err = p.Pick(labels, Options{})
+	assert.ErrorIs(t, err, ErrAbort)
+}
+
+func TestPromptReadsTerminal(t *testing.T) {
+	dir := t.TempDir()
+	saved := ttyPath
+	t.Cleanup(func() { ttyPath = saved })
+	ttyPath = filepath.Join(dir, "tty")
+	assert.NoError(t, os.WriteFile(ttyPath, []byte("2\n"), 0o600))
+
+	// input piped in for the remote command is left on stdin
+	stdin, err := os.Create(filepath.Join(dir, "stdin"))
+	assert.NoError(t, err)
+	_, err = stdin.WriteString("hostname\n")
+	assert.NoError(t, err)
+	_, err = stdin.Seek(0, 0)
+	assert.NoError(t, err)
+	savedStdin := os.Stdin
+	t.Cleanup(func() { os.Stdin = savedStdin; stdin.Close() })
+	os.Stdin = stdin
+
+	p := NewPrompt()
+	p.out = io.Discard
+	selected, err := p.Pick([]string{"prod api app", "stg api app"}, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, selected)
+
+	rest, err := io.ReadAll(os.Stdin)
+	assert.NoError(t, err)
+	assert.Equal(t, "hostname\n", string(rest))
+
+	ttyPath = filepath.Join(dir, "missing")
+	_, err = NewPrompt().Pick([]string{"prod api app"}, Options{})
+	assert.ErrorContains(t, err, "needs a terminal")
+
+	// without a terminal, stdin is read when it is free, taking no more
+	// than the selection
+	_, err = stdin.Seek(0, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, stdin.Truncate(0))
+	_, err = stdin.WriteString("1\nhostname\n")
+	assert.NoError(t, err)
+	_, err = stdin.Seek(0, 0)
+	assert.NoError(t, err)
+
+	p = NewPrompt()
+	p.Stdin, p.out = true, io.Discard
+	selected, err = p.Pick([]string{"prod api app"}, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0}, selected)
+
+	rest, err = io.ReadAll(os.Stdin)
+	assert.NoError(t, err)
+	assert.Equal(t, "hostname\n", string(rest))
+}
+
+func TestParseNumbers(t *testing.T) {
+	selected, err := parseNumbers("1, 3-5", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 2, 3, 4}, selected)
+
+	for _, answer := range []string{"0", "6", "a", "3-2", "1,"} {
+		_, err := parseNumbers(answer, 5)
+		assert.Error(t, err, answer)
+	}
+}
+
+func TestStripANSI(t *testing.T) {
+	assert.Equal(t, "prod api", StripANSI("\x1b[1;31mprod\x1b[0m api"))
+}
@@ -0,0 +1,148 @@
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ttyPath is the terminal the prompt reads from. stdin is only read when
+// there is no terminal and it carries no input for the remote command.
+var ttyPath = func() string {
+	if runtime.GOOS == "windows" {
+		return "CONIN$"
+	}
+	return "/dev/tty"
+}()
+
+// Prompt lists the entries with numbers and reads the chosen numbers, for
+// dumb terminals.
+type Prompt struct {
+	// Stdin lets the prompt read stdin when no terminal can be opened,
+	// such as in CI.
+	Stdin bool
+	in    io.Reader
+	out   io.Writer
+	color bool
+}
+
+// NewPrompt returns a Prompt on the terminal. Colours are left out on dumb
+// terminals and when NO_COLOR is set.
+func NewPrompt() *Prompt {
+	p := &Prompt{out: os.Stderr}
+	p.color = os.Getenv("TERM") != "dumb" && os.Getenv("NO_COLOR") == ""
+	return p
+}
+
+func newPrompt(in io.Reader, out io.Writer) *Prompt {
+	return &Prompt{in: in, out: out}
+}
+
+// open returns where the selection is read from.
+func (p *Prompt) open() (io.ReadCloser, error) {
+	if p.in != nil {
+		return io.NopCloser(p.in), nil
+	}
+	tty, err := os.Open(ttyPath)
+	if err == nil {
+		return tty, nil
+	}
+	if p.Stdin {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return nil, fmt.Errorf("the prompt picker needs a terminal: %w", err)
+}
+
+func (p *Prompt) Pick(labels []string, opts Options) ([]int, error) {
+	in, err := p.open()
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	if opts.Header != "" {
+		fmt.Fprintln(p.out, opts.Header)
+	}
+	if !p.color {
+		labels = stripAll(labels)
+	}
+	for i, label := range labels {
+		fmt.Fprintf(p.out, "%*d) %s\n", len(strconv.Itoa(len(labels))), i+1, label)
+	}
+
+	question := fmt.Sprintf("Select [1-%d]: ", len(labels))
+	if opts.Multi {
+		question = fmt.Sprintf("Select [1-%d], several as 1,3-4: ", len(labels))
+	}
+	for {
+		fmt.Fprint(p.out, question)
+		line, err := readLine(in)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read selection: %w", err)
+		}
+		answer := strings.TrimSpace(line)
+		if answer == "" || answer == "q" {
+			if errors.Is(err, io.EOF) {
+				fmt.Fprintln(p.out)
+			}
+			return nil, ErrAbort
+		}
+
+		selected, parseErr := parseNumbers(answer, len(labels))
+		if parseErr == nil && len(selected) > 1 && !opts.Multi {
+			parseErr = fmt.Errorf("select a single entry")
+		}
+		if parseErr == nil {
+			return selected, nil
+		}
+		fmt.Fprintln(p.out, parseErr)
+		if errors.Is(err, io.EOF) {
+			return nil, ErrAbort
+		}
+	}
+}
+
+// readLine reads up to and including the next newline. It reads a byte at
+// a time, so that nothing after the line is taken from in.
+func readLine(in io.Reader) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := in.Read(b)
+		if n > 0 {
+			line = append(line, b[0])
+			if b[0] == '\n' {
+				return string(line), nil
+			}
+		}
+		if err != nil {
+			return string(line), err
+		}
+	}
+}
+
+// parseNumbers parses a list of 1-based numbers and ranges like 1,3-4 into
+// indices.
+func parseNumbers(answer string, count int) ([]int, error) {
+	var selected []int
+	for _, part := range strings.Split(answer, ",") {
+		part = strings.TrimSpace(part)
+		first, last, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(strings.TrimSpace(first))
+		to := from
+		if err == nil && isRange {
+			to, err = strconv.Atoi(strings.TrimSpace(last))
+		}
+		if err != nil || from < 1 || to > count || from > to {
+			return nil, fmt.Errorf("invalid selection %q, enter numbers between 1 and %d", part, count)
+		}
+		for n := from; n <= to; n++ {
+			selected = append(selected, n-1)
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,250 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrDenied is returned when a policy forbids a command.
+var ErrDenied = errors.New("command denied by policy")
+
+const (
+	actionAllow = "allow"
+	actionDeny  = "deny"
+)
+
+// Policy restricts the commands that may be run with exec and the shells
+// started by login. Rules are
+// evaluated in order and the first rule matching the target decides.
+type Policy struct {
+	// Default is "allow" or "deny" and applies when no rule matches the
+	// target. It is "allow" when empty.
+	Default string `yaml:"default"`
+	Rules   []Rule `yaml:"rules"`
+}
+
+// Rule applies to the containers matched by its cluster, service and
+// container globs, an empty glob matches everything.
+//
+// Allow and Deny are globs matched against the command line, the argv
+// joined with spaces, where * also matches spaces and slashes. A command
+// matching Deny is refused; when Allow is set, only matching commands are
+// accepted.
+type Rule struct {
+	Name      string   `yaml:"name"`
+	Cluster   string   `yaml:"cluster"`
+	Service   string   `yaml:"service"`
+	Container string   `yaml:"container"`
+	Allow     []string `yaml:"allow"`
+	Deny      []string `yaml:"deny"`
+}
+
+// Target identifies the container a command is run in.
+type Target struct {
+	Cluster   string
+	Service   string
+	Container string
+}
+
+func (t Target) String() string {
+	return t.Cluster + "/" + t.Service + "/" + t.Container
+}
+
+// Decision is the result of evaluating a command against a policy.
+type Decision struct {
+	Allowed bool
+	// Rule names the deciding rule, "default" when no rule matched.
+	Rule   string
+	Reason string
+}
+
+// DeniedError describes a refused command.
+type DeniedError struct {
+	Target  Target
+	Command string
+	Decision
+}
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("%s: %q on %s (%s)", ErrDenied, e.Command, e.Target, e.Reason)
+}
+
+func (e *DeniedError) Unwrap() error {
+	return ErrDenied
+}
+
+// Load reads the policy file at path. A missing file yields nil, which
+// allows every command.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy %s: %w", path, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+func (p *Policy) validate() error {
+	switch p.Default {
+	case "", actionAllow, actionDeny:
+	default:
+		return fmt.Errorf("default must be %q or %q, got %q", actionAllow, actionDeny, p.Default)
+	}
+	for i, rule := range p.Rules {
+		for _, pattern := range []string{rule.Cluster, rule.Service, rule.Container} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("rule %d: bad pattern %q: %w", i+1, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Evaluate decides whether argv may be run in target. A nil policy allows
+// everything.
+func (p *Policy) Evaluate(target Target, argv []string) Decision {
+	if p == nil {
+		return Decision{Allowed: true, Rule: "default", Reason: "no policy"}
+	}
+
+	rule, name, ok := p.match(target)
+	if !ok {
+		return p.defaultDecision()
+	}
+	return rule.evaluate(name, strings.Join(argv, " "))
+}
+
+// EvaluateShell decides whether shell may be started in target. The
+// commands run in a shell cannot be checked, so a shell is refused where
+// the deciding rule has an allow list, and a shell that is not interactive,
+// reading its commands from piped input, where the rule has a deny list.
+func (p *Policy) EvaluateShell(target Target, shell string, interactive bool) Decision {
+	if p == nil {
+		return Decision{Allowed: true, Rule: "default", Reason: "no policy"}
+	}
+
+	rule, name, ok := p.match(target)
+	switch {
+	case !ok:
+		return p.defaultDecision()
+	case len(rule.Allow) > 0:
+		return Decision{Rule: name, Reason: fmt.Sprintf("%s allows only %s, not a shell", name, strings.Join(quoteAll(rule.Allow), ", "))}
+	case len(rule.Deny) > 0 && !interactive:
+		return Decision{Rule: name, Reason: fmt.Sprintf("%s denies commands, which cannot be checked in piped input", name)}
+	}
+	return rule.evaluate(name, shell)
+}
+
+// Check is Evaluate returning a *DeniedError for refused commands.
+func (p *Policy) Check(target Target, argv []string) error {
+	decision := p.Evaluate(target, argv)
+	if decision.Allowed {
+		return nil
+	}
+	return &DeniedError{Target: target, Command: strings.Join(argv, " "), Decision: decision}
+}
+
+// CheckShell is EvaluateShell returning a *DeniedError for refused shells.
+func (p *Policy) CheckShell(target Target, shell string, interactive bool) error {
+	decision := p.EvaluateShell(target, shell, interactive)
+	if decision.Allowed {
+		return nil
+	}
+	return &DeniedError{Target: target, Command: shell, Decision: decision}
+}
+
+// match returns the first rule matching target and its name.
+func (p *Policy) match(target Target) (Rule, string, bool) {
+	for i, rule := range p.Rules {
+		if !rule.matchesTarget(target) {
+			continue
+		}
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("rule %d", i+1)
+		}
+		return rule, name, true
+	}
+	return Rule{}, "", false
+}
+
+func (p *Policy) defaultDecision() Decision {
+	if p.Default == actionDeny {
+		return Decision{Rule: "default", Reason: "no rule matches the target and the default is deny"}
+	}
+	return Decision{Allowed: true, Rule: "default", Reason: "no rule matches the target"}
+}
+
+func (r Rule) matchesTarget(target Target) bool {
+	return matchName(r.Cluster, target.Cluster) &&
+		matchName(r.Service, target.Service) &&
+		matchName(r.Container, target.Container)
+}
+
+func (r Rule) evaluate(name, command string) Decision {
+	for _, pattern := range r.Deny {
+		if matchCommand(pattern, command) {
+			return Decision{Rule: name, Reason: fmt.Sprintf("%s denies %q", name, pattern)}
+		}
+	}
+	if len(r.Allow) == 0 {
+		return Decision{Allowed: true, Rule: name, Reason: fmt.Sprintf("%s has no allow list", name)}
+	}
+	for _, pattern := range r.Allow {
+		if matchCommand(pattern, command) {
+			return Decision{Allowed: true, Rule: name, Reason: fmt.Sprintf("%s allows %q", name, pattern)}
+		}
+	}
+	return Decision{Rule: name, Reason: fmt.Sprintf("%s allows only %s", name, strings.Join(quoteAll(r.Allow), ", "))}
+}
+
+func matchName(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
+
+// matchCommand matches a command line against a glob in which * matches
+// any text and ? a single character.
+func matchCommand(pattern, command string) bool {
+	var expr strings.Builder
+	expr.WriteString("(?s)^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String()).MatchString(command)
+}
+
+func quoteAll(patterns []string) []string {
+	quoted := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		quoted[i] = fmt.Sprintf("%q", pattern)
+	}
+	return quoted
+}
@@ -0,0 +1,153 @@
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPolicy = `
+default: allow
+rules:
+  - name: prod-app
+    cluster: "*prod*"
+    container: app
+    allow:
+      - "bin/rails console*"
+      - "cat *"
+    deny:
+      - "cat /etc/shadow"
+  - name: prod-locked
+    cluster: "*prod*"
+    allow: ["ls"]
+`
+
+func loadTestPolicy(t *testing.T, data string) *Policy {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	p, err := Load(path)
+	assert.NoError(t, err)
+	return p
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		p, err := Load(filepath.Join(t.TempDir(), "policy.yaml"))
+		assert.NoError(t, err)
+		assert.Nil(t, p)
+		assert.True(t, p.Evaluate(Target{}, []string{"rm", "-rf", "/"}).Allowed)
+	})
+
+	t.Run("bad default", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "policy.yaml")
+		assert.NoError(t, os.WriteFile(path, []byte("default: maybe"), 0o600))
+		_, err := Load(path)
+		assert.ErrorContains(t, err, "default must be")
+	})
+
+	t.Run("bad pattern", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "policy.yaml")
+		assert.NoError(t, os.WriteFile(path, []byte("rules: [{cluster: \"[\"}]"), 0o600))
+		_, err := Load(path)
+		assert.ErrorContains(t, err, "bad pattern")
+	})
+}
+
+func TestEvaluate(t *testing.T) {
+	p := loadTestPolicy(t, testPolicy)
+	app := Target{Cluster: "prod", Service: "api", Container: "app"}
+	sidecar := Target{Cluster: "prod", Service: "api", Container: "envoy"}
+
+	tests := []struct {
+		name    string
+		target  Target
+		argv    []string
+		allowed bool
+		rule    string
+	}{
+		{name: "allowed console", target: app, argv: []string{"bin/rails", "console", "-e", "production"}, allowed: true, rule: "prod-app"},
+		{name: "allowed cat", target: app, argv: []string{"cat", "/srv/app/REVISION"}, allowed: true, rule: "prod-app"},
+		{name: "denied over allowed", target: app, argv: []string{"cat", "/etc/shadow"}, rule: "prod-app"},
+		{name: "not allowed", target: app, argv: []string{"sh", "-c", "rm -rf /"}, rule: "prod-app"},
+		{name: "second rule", target: sidecar, argv: []string{"ls"}, allowed: true, rule: "prod-locked"},
+		{name: "second rule denies", target: sidecar, argv: []string{"cat", "x"}, rule: "prod-locked"},
+		{name: "no rule", target: Target{Cluster: "stg"}, argv: []string{"sh"}, allowed: true, rule: "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := p.Evaluate(tt.target, tt.argv)
+			assert.Equal(t, tt.allowed, decision.Allowed)
+			assert.Equal(t, tt.rule, decision.Rule)
+			assert.NotEmpty(t, decision.Reason)
+		})
+	}
+}
+
+func TestEvaluateShell(t *testing.T) {
+	p := loadTestPolicy(t, testPolicy+`
+  - name: stg-no-shadow
+    cluster: "*stg*"
+    deny: ["cat /etc/shadow"]
+  - name: dev-no-zsh
+    cluster: "*dev*"
+    deny: ["zsh"]
+`)
+	app := Target{Cluster: "prod", Service: "api", Container: "app"}
+	stg := Target{Cluster: "stg", Service: "api", Container: "app"}
+	dev := Target{Cluster: "dev", Service: "api", Container: "app"}
+
+	tests := []struct {
+		name        string
+		target      Target
+		shell       string
+		interactive bool
+		allowed     bool
+		rule        string
+	}{
+		{name: "allow list", target: app, shell: "bash", interactive: true, rule: "prod-app"},
+		{name: "deny list interactive", target: stg, shell: "bash", interactive: true, allowed: true, rule: "stg-no-shadow"},
+		{name: "deny list piped", target: stg, shell: "bash", rule: "stg-no-shadow"},
+		{name: "denied shell", target: dev, shell: "zsh", interactive: true, rule: "dev-no-zsh"},
+		{name: "no rule", target: Target{Cluster: "qa"}, shell: "bash", allowed: true, rule: "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := p.EvaluateShell(tt.target, tt.shell, tt.interactive)
+			assert.Equal(t, tt.allowed, decision.Allowed)
+			assert.Equal(t, tt.rule, decision.Rule)
+			assert.NotEmpty(t, decision.Reason)
+		})
+	}
+
+	var nilPolicy *Policy
+	assert.NoError(t, nilPolicy.CheckShell(app, "bash", false))
+	err := p.CheckShell(app, "bash", true)
+	assert.True(t, errors.Is(err, ErrDenied))
+	assert.ErrorContains(t, err, "not a shell")
+}
+
+func TestDefaultDeny(t *testing.T) {
+	p := loadTestPolicy(t, "default: deny\nrules: [{cluster: stg}]")
+	assert.True(t, p.Evaluate(Target{Cluster: "stg"}, []string{"sh"}).Allowed)
+	assert.False(t, p.Evaluate(Target{Cluster: "dev"}, []string{"sh"}).Allowed)
+}
+
+func TestCheck(t *testing.T) {
+	p := loadTestPolicy(t, testPolicy)
+	target := Target{Cluster: "prod", Service: "api", Container: "app"}
+
+	assert.NoError(t, p.Check(target, []string{"cat", "README"}))
+
+	err := p.Check(target, []string{"env"})
+	assert.True(t, errors.Is(err, ErrDenied))
+	var denied *DeniedError
+	assert.True(t, errors.As(err, &denied))
+	assert.Equal(t, "env", denied.Command)
+	assert.Contains(t, err.Error(), "prod/api/app")
+}
@@ -0,0 +1,339 @@
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Event types of asciicast v2.
+const (
+	EventOutput = "o"
+	EventInput  = "i"
+	EventResize = "r"
+)
+
+// Header is the first line of an asciicast v2 file.
+type Header struct {
+	Version       int               `json:"version"`
+	Width         int               `json:"width"`
+	Height        int               `json:"height"`
+	Timestamp     int64             `json:"timestamp,omitempty"`
+	IdleTimeLimit float64           `json:"idle_time_limit,omitempty"`
+	Title         string            `json:"title,omitempty"`
+	Env           map[string]string `json:"env,omitempty"`
+}
+
+// Event is a single line of an asciicast v2 file after the header.
+type Event struct {
+	// Time is the offset from the start of the recording.
+	Time time.Duration
+	Type string
+	Data string
+}
+
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{e.Time.Seconds(), e.Type, e.Data})
+}
+
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var fields []any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if len(fields) != 3 {
+		return fmt.Errorf("event has %d fields, expected 3", len(fields))
+	}
+	seconds, ok1 := fields[0].(float64)
+	eventType, ok2 := fields[1].(string)
+	eventData, ok3 := fields[2].(string)
+	if !ok1 || !ok2 || !ok3 {
+		return fmt.Errorf("malformed event %s", data)
+	}
+	e.Time = time.Duration(seconds * float64(time.Second))
+	e.Type = eventType
+	e.Data = eventData
+	return nil
+}
+
+// Writer writes a terminal session as asciicast v2. It is safe for
+// concurrent use by the goroutines copying input and output.
+type Writer struct {
+	mu      sync.Mutex
+	w       *bufio.Writer
+	start   time.Time
+	now     func() time.Time
+	pending map[string][]byte
+	// shown is the redacted part of the pending line that has already been
+	// recorded by an idle flush.
+	shown  map[string]string
+	redact func(string) string
+	err    error
+	closed bool
+	// idle is how long the rest of a line is waited for before what there
+	// is of it is recorded.
+	idle   time.Duration
+	timers map[string]*time.Timer
+}
+
+// maxPending bounds the data held back while waiting for a line break.
+const maxPending = 64 * 1024
+
+// idleFlush is how long the rest of a line is waited for before what there
+// is of it is recorded, so that prompts and echoed keystrokes keep the
+// timing they had.
+const idleFlush = 100 * time.Millisecond
+
+// NewWriter writes header to w and returns a Writer for the events that
+// follow it.
+func NewWriter(w io.Writer, header Header) (*Writer, error) {
+	header.Version = 2
+	start := time.Now()
+	if header.Timestamp == 0 {
+		header.Timestamp = start.Unix()
+	}
+
+	bw := bufio.NewWriter(w)
+	line, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode asciicast header: %w", err)
+	}
+	if _, err := bw.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write asciicast header: %w", err)
+	}
+	return &Writer{
+		w:       bw,
+		start:   start,
+		now:     time.Now,
+		pending: map[string][]byte{},
+		shown:   map[string]string{},
+		idle:    idleFlush,
+		timers:  map[string]*time.Timer{},
+	}, nil
+}
+
+// Redact filters the data of output events through f and stops recording
+// input, which would hold typed secrets f cannot recognise.
+//
+// Output is redacted a line at a time, so that a secret echoed a character
+// at a time is still seen whole by f. When a line does not end within a
+// moment, like a prompt, what there is of it is recorded redacted, and the
+// rest of the line is recorded once the line has been redacted again as a
+// whole.
+func (w *Writer) Redact(f func(string) string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.redact = f
+}
+
+// Output records data written to the terminal.
+func (w *Writer) Output(p []byte) {
+	w.write(EventOutput, p)
+}
+
+// Input records data typed into the terminal.
+func (w *Writer) Input(p []byte) {
+	w.write(EventInput, p)
+}
+
+// Resize records a change of the terminal size.
+func (w *Writer) Resize(width, height int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.event(EventResize, fmt.Sprintf("%dx%d", width, height))
+}
+
+// write records p as an event, holding back a trailing incomplete UTF-8
+// sequence until the rest of it arrives, or the rest of the line when
+// redacting.
+func (w *Writer) write(eventType string, p []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.redact != nil && eventType == EventInput {
+		return
+	}
+
+	data := append(w.pending[eventType], p...)
+	n := completeUTF8(data)
+	if w.redact != nil && n < maxPending {
+		n = bytes.LastIndexAny(data[:n], "\r\n") + 1
+	}
+	w.pending[eventType] = append([]byte(nil), data[n:]...)
+	if n > 0 {
+		w.emit(eventType, string(data[:n]))
+		w.shown[eventType] = ""
+	}
+	if w.redact != nil && len(w.pending[eventType]) > 0 {
+		w.flushWhenIdle(eventType)
+	}
+}
+
+// flushWhenIdle records the line held back for eventType so far once
+// nothing more has been written for it for w.idle.
+func (w *Writer) flushWhenIdle(eventType string) {
+	if timer := w.timers[eventType]; timer != nil {
+		timer.Stop()
+	}
+	w.timers[eventType] = time.AfterFunc(w.idle, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if !w.closed {
+			pending := w.pending[eventType]
+			w.shown[eventType] = w.emit(eventType, string(pending[:completeUTF8(pending)]))
+		}
+	})
+}
+
+// emit records data, the start of which may have been recorded by an idle
+// flush already, and returns data as recorded. When redaction masks part
+// of what was recorded before, the data is recorded again from where the
+// two differ.
+func (w *Writer) emit(eventType, data string) string {
+	if w.redact != nil && eventType != EventResize {
+		data = w.redact(data)
+	}
+	shown := w.shown[eventType]
+	i := 0
+	for i < len(data) && i < len(shown) && data[i] == shown[i] {
+		i++
+	}
+	for i > 0 && i < len(data) && !utf8.RuneStart(data[i]) {
+		i--
+	}
+	if i < len(data) {
+		w.event(eventType, data[i:])
+	}
+	return data
+}
+
+func (w *Writer) event(eventType, data string) {
+	if w.err != nil {
+		return
+	}
+	line, err := json.Marshal(Event{Time: w.now().Sub(w.start), Type: eventType, Data: data})
+	if err == nil {
+		_, err = w.w.Write(append(line, '\n'))
+	}
+	w.err = err
+}
+
+// Close flushes the recording and reports the first error it ran into.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+	for _, eventType := range []string{EventInput, EventOutput} {
+		if timer := w.timers[eventType]; timer != nil {
+			timer.Stop()
+		}
+		if len(w.pending[eventType]) > 0 {
+			w.emit(eventType, string(w.pending[eventType]))
+			w.pending[eventType] = nil
+		}
+	}
+	if w.err != nil {
+		return fmt.Errorf("failed to write asciicast: %w", w.err)
+	}
+	if err := w.w.Flush(); err != nil {
+		return fmt.Errorf("failed to write asciicast: %w", err)
+	}
+	return nil
+}
+
+// completeUTF8 returns the length of the longest prefix of p that does not
+// end in the middle of a UTF-8 sequence.
+func completeUTF8(p []byte) int {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(p[i]) {
+			continue
+		}
+		if !utf8.FullRune(p[i:]) {
+			return i
+		}
+		break
+	}
+	return len(p)
+}
+
+// Read parses an asciicast v2 file.
+func Read(r io.Reader) (Header, []Event, error) {
+	var header Header
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return header, nil, fmt.Errorf("failed to read asciicast: %w", err)
+		}
+		return header, nil, fmt.Errorf("asciicast is empty")
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return header, nil, fmt.Errorf("failed to parse asciicast header: %w", err)
+	}
+	if header.Version != 2 {
+		return header, nil, fmt.Errorf("unsupported asciicast version %d", header.Version)
+	}
+
+	var events []Event
+	for line := 2; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return header, nil, fmt.Errorf("failed to parse asciicast line %d: %w", line, err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return header, nil, fmt.Errorf("failed to read asciicast: %w", err)
+	}
+	return header, events, nil
+}
+
+// ReplayOptions controls the pace of Replay.
+type ReplayOptions struct {
+	// Speed divides the delays between events, 1 when zero.
+	Speed float64
+	// IdleLimit caps the delay between two events, unlimited when zero.
+	IdleLimit time.Duration
+	// Sleep defaults to time.Sleep.
+	Sleep func(time.Duration)
+}
+
+// Replay writes the output events to w with the delays they were recorded
+// with.
+func Replay(w io.Writer, events []Event, opts ReplayOptions) error {
+	if opts.Speed <= 0 {
+		opts.Speed = 1
+	}
+	if opts.Sleep == nil {
+		opts.Sleep = time.Sleep
+	}
+
+	var last time.Duration
+	for _, event := range events {
+		if event.Type != EventOutput {
+			continue
+		}
+		delay := event.Time - last
+		last = event.Time
+		if opts.IdleLimit > 0 && delay > opts.IdleLimit {
+			delay = opts.IdleLimit
+		}
+		if delay > 0 {
+			opts.Sleep(time.Duration(float64(delay) / opts.Speed))
+		}
+		if _, err := io.WriteString(w, event.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
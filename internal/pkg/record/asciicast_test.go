@@ -0,0 +1,171 @@
+package record
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriterAndRead(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, Header{Width: 120, Height: 40, Title: "prod/api/abc"})
+	assert.NoError(t, err)
+
+	clock := w.start
+	w.now = func() time.Time { return clock }
+
+	clock = clock.Add(500 * time.Millisecond)
+	w.Input([]byte("ls\r"))
+	clock = clock.Add(time.Second)
+	// "é" split over two writes
+	w.Output([]byte("caf\xc3"))
+	w.Output([]byte("\xa9\r\n"))
+	w.Resize(100, 30)
+	assert.NoError(t, w.Close())
+
+	header, events, err := Read(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, header.Version)
+	assert.Equal(t, 120, header.Width)
+	assert.Equal(t, "prod/api/abc", header.Title)
+	assert.NotZero(t, header.Timestamp)
+
+	assert.Equal(t, []Event{
+		{Time: 500 * time.Millisecond, Type: EventInput, Data: "ls\r"},
+		{Time: 1500 * time.Millisecond, Type: EventOutput, Data: "caf"},
+		{Time: 1500 * time.Millisecond, Type: EventOutput, Data: "é\r\n"},
+		{Time: 1500 * time.Millisecond, Type: EventResize, Data: "100x30"},
+	}, events)
+}
+
+func TestCompleteUTF8(t *testing.T) {
+	assert.Equal(t, 3, completeUTF8([]byte("abc")))
+	assert.Equal(t, 1, completeUTF8([]byte("a\xe3\x81")))
+	assert.Equal(t, 4, completeUTF8([]byte("a\xe3\x81\x82")))
+	assert.Equal(t, 2, completeUTF8([]byte("a\xff")))
+	assert.Equal(t, 0, completeUTF8(nil))
+}
+
+func TestReadErrors(t *testing.T) {
+	_, _, err := Read(strings.NewReader(""))
+	assert.Error(t, err)
+
+	_, _, err = Read(strings.NewReader(`{"version": 1}`))
+	assert.ErrorContains(t, err, "unsupported asciicast version")
+
+	_, _, err = Read(strings.NewReader("{\"version\": 2}\n[1, \"o\"]\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestReplay(t *testing.T) {
+	events := []Event{
+		{Time: time.Second, Type: EventOutput, Data: "a"},
+		{Time: 2 * time.Second, Type: EventInput, Data: "x"},
+		{Time: 11 * time.Second, Type: EventOutput, Data: "b"},
+	}
+
+	var out bytes.Buffer
+	var slept []time.Duration
+	err := Replay(&out, events, ReplayOptions{
+		Speed:     2,
+		IdleLimit: 3 * time.Second,
+		Sleep:     func(d time.Duration) { slept = append(slept, d) },
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ab", out.String())
+	assert.Equal(t, []time.Duration{500 * time.Millisecond, 1500 * time.Millisecond}, slept)
+}
+
+func TestWriterRedact(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, Header{Width: 80, Height: 24})
+	assert.NoError(t, err)
+	w.Redact(func(s string) string {
+		return strings.ReplaceAll(s, "hunter2", "[REDACTED]")
+	})
+
+	w.Input([]byte("echo hun"))
+	w.Input([]byte("ter2\r"))
+	w.Output([]byte("hunter"))
+	w.Output([]byte("2\r\nprompt$ "))
+	assert.NoError(t, w.Close())
+
+	_, events, err := Read(&buf)
+	assert.NoError(t, err)
+	var data []string
+	for _, event := range events {
+		data = append(data, event.Type+":"+event.Data)
+	}
+	assert.Equal(t, []string{
+		"o:[REDACTED]\r\n",
+		"o:prompt$ ",
+	}, data)
+}
+
+func TestWriterRedactFlushesWhenIdle(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, Header{Width: 80, Height: 24})
+	assert.NoError(t, err)
+	w.idle = time.Millisecond
+	w.Redact(func(s string) string { return s })
+
+	w.Output([]byte("prompt$ "))
+	waitForIdleFlush(t, w)
+	w.Output([]byte("ls\r\n"))
+	assert.NoError(t, w.Close())
+
+	assert.Equal(t, []string{"o:prompt$ ", "o:ls\r\n"}, recordedEvents(t, &buf))
+}
+
+func TestWriterRedactSecretTypedByCharacter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, Header{Width: 80, Height: 24})
+	assert.NoError(t, err)
+	w.idle = time.Millisecond
+	w.Redact(func(s string) string {
+		return regexp.MustCompile(`(PASSWORD=)\S+`).ReplaceAllString(s, "${1}[REDACTED]")
+	})
+
+	for _, c := range "$ export PASSWORD=hunter2\r\n" {
+		w.Input([]byte(string(c)))
+		w.Output([]byte(string(c)))
+		waitForIdleFlush(t, w)
+	}
+	assert.NoError(t, w.Close())
+
+	var output strings.Builder
+	for _, event := range recordedEvents(t, &buf) {
+		assert.NotContains(t, event, "h", "a part of the secret was recorded")
+		data, ok := strings.CutPrefix(event, "o:")
+		assert.True(t, ok, "input was recorded: %q", event)
+		output.WriteString(data)
+	}
+	assert.Equal(t, "$ export PASSWORD=[REDACTED]\r\n", output.String())
+}
+
+// waitForIdleFlush waits until the output line held back by w has been
+// recorded by an idle flush.
+func waitForIdleFlush(t *testing.T, w *Writer) {
+	t.Helper()
+	assert.Eventually(t, func() bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return w.shown[EventOutput] == w.redact(string(w.pending[EventOutput]))
+	}, time.Second, time.Millisecond)
+}
+
+func recordedEvents(t *testing.T, r io.Reader) []string {
+	t.Helper()
+	_, events, err := Read(r)
+	assert.NoError(t, err)
+	var data []string
+	for _, event := range events {
+		data = append(data, event.Type+":"+event.Data)
+	}
+	return data
+}
@@ -0,0 +1,128 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// Mask replaces redacted values.
+const Mask = "[REDACTED]"
+
+// assignedValue matches the value of KEY=value, KEY: "value" and the like,
+// quoted or not.
+const assignedValue = `["']?\s*[=:]\s*(?:"([^"]*)"|'([^']*)'|([^\s"',;&|}]+))`
+
+// builtinPatterns match common credentials. When a pattern has capture
+// groups, only the last group that matched is masked so that the
+// surrounding key stays readable.
+var builtinPatterns = []string{
+	// AWS access key IDs
+	`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`,
+	// AWS secret access keys
+	`(?i)secret_?access_?key["']?\s*[=:]\s*["']?([A-Za-z0-9/+=]{40})`,
+	// GitHub tokens
+	`\bgh[pousr]_[A-Za-z0-9]{36,}\b`,
+	// Slack tokens
+	`\bxox[abprs]-[A-Za-z0-9-]{10,}`,
+	// JSON web tokens
+	`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`,
+	// bearer tokens
+	`(?i)\bbearer\s+([A-Za-z0-9._~+/=-]{16,})`,
+	// passwords in URLs
+	`://[^:/\s]+:([^@/\s]+)@`,
+	// password=..., "api_key": "...", DB_PASSWORD=..., GITHUB_TOKEN: ...
+	`(?i)\b[A-Za-z0-9_]*(?:password|passwd|secret|token|api_?key)[A-Za-z0-9_]*` + assignedValue,
+}
+
+// Redactor masks secret values in text. A nil *Redactor leaves text
+// unchanged. It is safe for concurrent use.
+type Redactor struct {
+	mu       sync.RWMutex
+	patterns []*regexp.Regexp
+	names    map[string]bool
+}
+
+// New returns a Redactor for the built-in patterns and the extra regular
+// expressions given in patterns.
+func New(patterns []string) (*Redactor, error) {
+	r := &Redactor{names: map[string]bool{}}
+	for _, pattern := range builtinPatterns {
+		r.patterns = append(r.patterns, regexp.MustCompile(pattern))
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// AddSecretNames masks the values assigned to the named variables, such as
+// the secrets of a task definition, wherever they show up as NAME=value or
+// "NAME": "value". A value showing up on its own, as printed by
+// printenv NAME, is not recognised.
+func (r *Redactor) AddSecretNames(names ...string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var added []string
+	for _, name := range names {
+		if name != "" && !r.names[name] {
+			r.names[name] = true
+			added = append(added, name)
+		}
+	}
+	sort.Strings(added)
+	for _, name := range added {
+		r.patterns = append(r.patterns, regexp.MustCompile(
+			`\b`+regexp.QuoteMeta(name)+assignedValue))
+	}
+}
+
+// String returns s with every secret replaced by Mask.
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, re := range r.patterns {
+		s = mask(re, s)
+	}
+	return s
+}
+
+func mask(re *regexp.Regexp, s string) string {
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+
+	out := make([]byte, 0, len(s))
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		for i := len(m) - 2; i >= 2; i -= 2 {
+			if m[i] >= 0 {
+				start, end = m[i], m[i+1]
+				break
+			}
+		}
+		if start < last {
+			continue
+		}
+		out = append(out, s[last:start]...)
+		out = append(out, Mask...)
+		last = end
+	}
+	out = append(out, s[last:]...)
+	return string(out)
+}